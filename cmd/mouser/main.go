@@ -65,21 +65,16 @@ func main() {
 		conf.Settings.Debug = true
 	}
 
-	run, stop, err := bootstrap.Bootstrap(conf)
-	if err != nil {
+	e := bootstrap.NewEngine(conf)
+	if err := e.Start(); err != nil {
 		abort(1, err)
 	}
 
 	sigCh := make(chan os.Signal, 2)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		if err := stop(); err != nil {
-			abort(1, err)
-		}
-	}()
-
-	if err := run(); err != nil {
+	<-sigCh
+	// Stopping awaits the shutdown of processes started by actions.
+	if err := e.Stop(); err != nil {
 		abort(1, err)
 	}
 }
@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"container/list"
+	"sync"
+)
+
+// actionCache holds a bounded, concurrency-safe least-recently-used cache of
+// actions by key, e.g. of app-path-based lookups.
+type actionCache struct {
+	cap   int
+	ll    *list.List
+	items map[string]*list.Element
+	mx    sync.Mutex
+}
+
+type actionCacheEntry struct {
+	key    string
+	action Action
+}
+
+func newActionCache(cap int) *actionCache {
+	return &actionCache{
+		cap:   cap,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, cap),
+	}
+}
+
+func (c *actionCache) get(key string) (Action, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*actionCacheEntry).action, true
+	}
+	return nil, false
+}
+
+func (c *actionCache) set(key string, action Action) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*actionCacheEntry).action = action
+		return
+	}
+	c.items[key] = c.ll.PushFront(&actionCacheEntry{key, action})
+	for c.ll.Len() > c.cap {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.items, el.Value.(*actionCacheEntry).key)
+	}
+}
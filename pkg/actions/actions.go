@@ -2,60 +2,84 @@
 package actions
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/go-vgo/robotgo"
 )
 
 // Action describes an action function.
-type Action func()
+//
+// Actions return once done, and report failures as errors. Long-running
+// actions stop early once ctx is cancelled, returning the context error.
+type Action func(ctx context.Context, ev Trigger) error
+
+// Trigger holds the origin of an action trigger.
+type Trigger struct {
+	// Key is the triggering hotkey, if any.
+	Key string
+	// Gestures are the triggering gestures, if any.
+	Gestures []string
+	// T is the trigger time.
+	T time.Time
+}
 
 // ActionCreator describes a function that creates an Action.
 type ActionCreator func(args ...interface{}) (Action, error)
 
 // Actions errors raised by package actions.
 var (
-	ErrInvalidActionName = errors.New("action name is invalid")
-	ErrInvalidActionArgs = errors.New("action arguments are invalid")
+	ErrInvalidActionName    = errors.New("action name is invalid")
+	ErrInvalidActionArgs    = errors.New("action arguments are invalid")
+	ErrDuplicateAction      = errors.New("action name is already registered")
+	ErrInvalidActionCreator = errors.New("action creator is missing")
 )
 
-// New creates an action.
+// New creates an action of the default registry (see DefaultRegistry).
 func New(actionName string, args ...interface{}) (Action, error) {
-	if action, ok := basicActions[actionName]; ok {
-		if len(args) != 0 {
-			return nil, ErrInvalidActionArgs
-		}
-		return action, nil
-	}
-	if actionCreator, ok := actionCreators[actionName]; ok {
-		return actionCreator(args...)
-	}
-	return nil, ErrInvalidActionName
+	return DefaultRegistry().New(actionName, args...)
 }
 
 var basicActions = map[string]Action{
 	// vol:down decreases the audio volume level.
-	"vol:down": func() { robotgo.KeyTap("audio_vol_down") },
+	"vol:down": newSystemKeyTap("audio_vol_down"),
 	// vol:up increases the audio volume level.
-	"vol:up": func() { robotgo.KeyTap("audio_vol_up") },
+	"vol:up": newSystemKeyTap("audio_vol_up"),
 	// vol:mute toggles between muting and unmuting audio.
-	"vol:mute": func() { robotgo.KeyTap("audio_mute") },
+	"vol:mute": newSystemKeyTap("audio_mute"),
 
 	// media:toggle toggles between playing and pausing the current media.
-	"media:toggle": func() { robotgo.KeyTap("audio_play") },
+	"media:toggle": newSystemKeyTap("audio_play"),
 	// media:prev rewindes the current or jumps back to the previous media record.
-	"media:prev": func() { robotgo.KeyTap("audio_prev") },
+	"media:prev": newSystemKeyTap("audio_prev"),
 	// media:prev forwards to the next media record.
-	"media:next": func() { robotgo.KeyTap("audio_next") },
+	"media:next": newSystemKeyTap("audio_next"),
 
 	// os:close-window closes the current window.
-	"os:close-window": func() { robotgo.CloseWindow() },
+	"os:close-window": func(context.Context, Trigger) error {
+		DefaultDriver().CloseWindow()
+		return nil
+	},
+
+	// clip:copy-selection copies the current selection to the clipboard.
+	"clip:copy-selection": func(context.Context, Trigger) error {
+		return DefaultClipboard().CopySelection()
+	},
+	// clip:paste pastes the current clipboard contents.
+	"clip:paste": func(context.Context, Trigger) error {
+		return DefaultClipboard().Paste()
+	},
+	// clip:paste-prev pastes the previous clipboard history entry; consecutive
+	// calls step further back through the history.
+	"clip:paste-prev": func(context.Context, Trigger) error {
+		return DefaultClipboard().PastePrev()
+	},
 
 	// misc:none does nothing.
-	"misc:none": func() {},
+	"misc:none": func(context.Context, Trigger) error { return nil },
 }
 
 var actionCreators = map[string]ActionCreator{
@@ -64,32 +88,61 @@ var actionCreators = map[string]ActionCreator{
 	// - modifiers ...string: Optional modifiers to hold during the key tap, e.g.
 	//   "shift", "command", etc.
 	// - key string: The name of the key to tap, e.g. "f1", "a", "enter" etc.
+	//   Characters are resolved through the active keyboard layout, while
+	//   "phys:<us-key>" or "sc:<scancode>" address physical keys.
 	"io:tap": func(args ...interface{}) (Action, error) {
-		l := len(args)
-		if l < 1 {
+		key, modifiers, ok := parseKeyCombo(args)
+		if !ok {
 			return nil, ErrInvalidActionArgs
-		} else if key, ok := stringifySingle(args[l-1]); !ok {
+		}
+		return NewTap(nil, key, modifiers...), nil
+	},
+	// io:key-down presses and holds a key until released via io:key-up.
+	// Arguments:
+	// - modifiers ...string: Optional modifiers to press & hold before the key,
+	//   e.g. "shift", "command", etc.
+	// - key string: The name of the key to press & hold.
+	"io:key-down": func(args ...interface{}) (Action, error) {
+		key, modifiers, ok := parseKeyCombo(args)
+		if !ok {
+			return nil, ErrInvalidActionArgs
+		}
+		return NewKeyDown(nil, key, modifiers...), nil
+	},
+	// io:key-up releases a key held via io:key-down.
+	// Arguments:
+	// - modifiers ...string: Optional modifiers to release after the key.
+	// - key string: The name of the key to release.
+	"io:key-up": func(args ...interface{}) (Action, error) {
+		key, modifiers, ok := parseKeyCombo(args)
+		if !ok {
 			return nil, ErrInvalidActionArgs
-		} else {
-			modifiers, ok := stringify(args[:l-1])
-			if !ok {
-				return nil, ErrInvalidActionArgs
-			}
-			tap := func() { robotgo.KeyTap(key, destringify(modifiers)...) }
-			return tap, nil
 		}
+		return NewKeyUp(nil, key, modifiers...), nil
 	},
 	// io:type writes out the given text.
 	// Arguments:
 	// - text string: The text to type out.
+	// - options map: Optional options:
+	//   - keys bool: Whether to parse text as a key sequence (see
+	//     ParseKeySequence).
+	//   - char-delay int: The pause between typed characters in milliseconds.
+	//   - key-delay int: The pause after key taps in milliseconds.
 	"io:type": func(args ...interface{}) (Action, error) {
+		args, optsArg := splitActionOptions(args)
+		var opts TypeOptions
+		if optsArg != nil {
+			var ok bool
+			if opts, ok = parseTypeOptions(optsArg); !ok {
+				return nil, ErrInvalidActionArgs
+			}
+		}
 		if len(args) != 1 {
 			return nil, ErrInvalidActionArgs
 		} else if text, ok := stringifySingle(args[0]); !ok {
 			return nil, ErrInvalidActionArgs
 		} else {
-			write := func() { robotgo.TypeStr(text) }
-			return write, nil
+			return NewType(nil, text, opts)
 		}
 	},
 	// io:scroll triggers a scroll event.
@@ -104,41 +157,243 @@ var actionCreators = map[string]ActionCreator{
 		} else if y, ok := args[1].(int); !ok {
 			return nil, ErrInvalidActionArgs
 		} else {
-			scroll := func() { robotgo.Scroll(-x, -y) }
-			return scroll, nil
+			return NewScroll(nil, x, y), nil
+		}
+	},
+	// io:click clicks a mouse button.
+	// Arguments:
+	// - button string: Optional mouse button, i.e. "left" (default), "right" or
+	//   "center".
+	// - count int: Optional number of clicks, e.g. 2 for a double click
+	//   (defaults to 1).
+	"io:click": func(args ...interface{}) (Action, error) {
+		if len(args) > 2 {
+			return nil, ErrInvalidActionArgs
+		}
+		button, count := "", 1
+		if len(args) > 0 {
+			var ok bool
+			if button, ok = args[0].(string); !ok {
+				return nil, ErrInvalidActionArgs
+			}
+		}
+		if len(args) > 1 {
+			var ok bool
+			if count, ok = args[1].(int); !ok {
+				return nil, ErrInvalidActionArgs
+			}
+		}
+		return NewClick(nil, button, count)
+	},
+	// io:move moves the pointer.
+	// Arguments:
+	// - x number: The horizontal target position.
+	// - y number: The vertical target position.
+	// - mode string: Optional position mode, i.e. "abs" for absolute screen
+	//   coordinates (default), "rel" for a position relative to the current
+	//   pointer position, or "pct" for a percentage of the screen size.
+	"io:move": func(args ...interface{}) (Action, error) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, ErrInvalidActionArgs
+		}
+		x, y, mode, ok := parsePointerArgs(args)
+		if !ok {
+			return nil, ErrInvalidActionArgs
+		}
+		return NewMove(nil, x, y, mode)
+	},
+	// io:drag drags the pointer from its current position while holding a mouse
+	// button.
+	// Arguments:
+	// - x number: The horizontal target position.
+	// - y number: The vertical target position.
+	// - mode string: Optional position mode (see io:move).
+	// - button string: Optional mouse button (see io:click).
+	"io:drag": func(args ...interface{}) (Action, error) {
+		if len(args) < 2 || len(args) > 4 {
+			return nil, ErrInvalidActionArgs
+		}
+		x, y, mode, ok := parsePointerArgs(args[:min(len(args), 3)])
+		if !ok {
+			return nil, ErrInvalidActionArgs
+		}
+		button := ""
+		if len(args) > 3 {
+			if button, ok = args[3].(string); !ok {
+				return nil, ErrInvalidActionArgs
+			}
+		}
+		return NewDrag(nil, x, y, mode, button)
+	},
+	// io:mouse-down presses a mouse button.
+	// Arguments:
+	// - button string: Optional mouse button (see io:click).
+	"io:mouse-down": func(args ...interface{}) (Action, error) {
+		button, ok := parseOptionalString(args)
+		if !ok {
+			return nil, ErrInvalidActionArgs
+		}
+		return NewMouseToggle(nil, button, true)
+	},
+	// io:mouse-up releases a mouse button.
+	// Arguments:
+	// - button string: Optional mouse button (see io:click).
+	"io:mouse-up": func(args ...interface{}) (Action, error) {
+		button, ok := parseOptionalString(args)
+		if !ok {
+			return nil, ErrInvalidActionArgs
 		}
+		return NewMouseToggle(nil, button, false)
 	},
 
-	// os:open opens a file or application.
+	// os:open opens a file, URL or application via the platform's opener.
 	// Arguments:
-	// - file string: The path to the file or application to open.
-	// - openArgs ...string: List of extra arguments to pass to the open command.
+	// - target string: The path to the file or application, a URL, or a
+	//   desktop entry (Linux).
+	// - openArgs ...string: List of extra arguments to pass to the opener
+	//   (macOS only).
+	// - options map: Optional options:
+	//   - app string: The application to open target with.
+	//   - app-args []string: List of extra arguments to pass to the opened
+	//     application, if supported.
 	"os:open": func(args ...interface{}) (Action, error) {
+		args, optsArg := splitActionOptions(args)
 		if len(args) < 1 {
 			return nil, ErrInvalidActionArgs
-		} else if app, ok := stringifySingle(args[0]); !ok {
+		} else if target, ok := stringifySingle(args[0]); !ok || target == "" {
+			return nil, ErrInvalidActionArgs
+		} else if openArgs, ok := stringify(args[1:]); !ok {
+			return nil, ErrInvalidActionArgs
+		} else if o, ok := parseOpen(target, openArgs, optsArg); !ok {
 			return nil, ErrInvalidActionArgs
 		} else {
-			openArgs, ok := stringify(args[1:])
-			if !ok {
+			return NewOpen(o)
+		}
+	},
+	// os:cmd runs a custom command.
+	// Arguments:
+	// - cmd string: The command name or path (or a script with the shell
+	//   option). Scripts must not contain placeholders (see NewTemplated).
+	// - cmdArgs ...string: List of extra arguments to pass to the command (or
+	//   positional parameters of the script).
+	// - options map: Optional options:
+	//   - shell bool|string: Whether to run cmd as a script via the default
+	//     shell, or via a given shell.
+	//   - env map: Additional environment variables.
+	//   - cwd string: The working directory.
+	//   - stdin string: The standard input.
+	//   - timeout int: The time in milliseconds after which to kill the command.
+	//   - wait bool: Whether to block until the command exited.
+	//   - output string: The output mode, i.e. "discard" (default), "log",
+	//     "type", "clipboard" or "notify".
+	//   - policy string: The instance policy, i.e. "parallel" (default),
+	//     "single-instance" or "restart".
+	"os:cmd": func(args ...interface{}) (Action, error) {
+		args, optsArg := splitActionOptions(args)
+		if len(args) < 1 {
+			return nil, ErrInvalidActionArgs
+		} else if cmdName, ok := stringifySingle(args[0]); !ok {
+			return nil, ErrInvalidActionArgs
+		} else if cmdArgs, ok := stringify(args[1:]); !ok {
+			return nil, ErrInvalidActionArgs
+		} else if c, ok := parseCmd(cmdName, cmdArgs, optsArg); !ok {
+			return nil, ErrInvalidActionArgs
+		} else {
+			return NewCmd(c, nil)
+		}
+	},
+
+	// ui:notify shows a desktop notification.
+	// Arguments:
+	// - title string: The notification title.
+	// - body string: The notification body.
+	// - urgency string: Optional urgency, i.e. "low", "normal" (default) or
+	//   "critical".
+	"ui:notify": func(args ...interface{}) (Action, error) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, ErrInvalidActionArgs
+		} else if strs, ok := stringify(args); !ok {
+			return nil, ErrInvalidActionArgs
+		} else {
+			n := Notification{Title: strs[0], Body: strs[1]}
+			if len(strs) > 2 {
+				n.Urgency = strs[2]
+			}
+			if CheckUrgency(n.Urgency) != nil {
 				return nil, ErrInvalidActionArgs
 			}
-			cmdArgs := make([]string, len(args))
-			copy(cmdArgs, openArgs)
-			cmdArgs[len(openArgs)] = app
-			cmd := exec.Command("open", cmdArgs...)
-			open := func() {
-				c := *cmd
-				c.Start()
+			return NewNotify(nil, n)
+		}
+	},
+
+	// var:set sets a state variable.
+	// Arguments:
+	// - name string: The variable name.
+	// - value string|number|bool: The variable value.
+	"var:set": func(args ...interface{}) (Action, error) {
+		if len(args) != 2 {
+			return nil, ErrInvalidActionArgs
+		} else if name, ok := args[0].(string); !ok || name == "" {
+			return nil, ErrInvalidActionArgs
+		} else if b, ok := args[1].(bool); ok {
+			return NewVarSet(nil, name, strconv.FormatBool(b))
+		} else if value, ok := stringifySingle(args[1]); !ok {
+			return nil, ErrInvalidActionArgs
+		} else {
+			return NewVarSet(nil, name, value)
+		}
+	},
+	// var:inc increments a numeric state variable.
+	// Arguments:
+	// - name string: The variable name.
+	// - n int: Optional increment; defaults to 1.
+	"var:inc": func(args ...interface{}) (Action, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, ErrInvalidActionArgs
+		} else if name, ok := args[0].(string); !ok || name == "" {
+			return nil, ErrInvalidActionArgs
+		} else if len(args) == 1 {
+			return NewVarInc(nil, name, 1)
+		} else if n, ok := args[1].(int); !ok {
+			return nil, ErrInvalidActionArgs
+		} else {
+			return NewVarInc(nil, name, n)
+		}
+	},
+	// var:toggle flips a boolean state variable.
+	// Arguments:
+	// - name string: The variable name.
+	"var:toggle": func(args ...interface{}) (Action, error) {
+		if len(args) != 1 {
+			return nil, ErrInvalidActionArgs
+		} else if name, ok := args[0].(string); !ok || name == "" {
+			return nil, ErrInvalidActionArgs
+		} else {
+			return NewVarToggle(nil, name)
+		}
+	},
+
+	// clip:set writes text to the clipboard.
+	// Arguments:
+	// - text string: The text to write to the clipboard.
+	"clip:set": func(args ...interface{}) (Action, error) {
+		if len(args) != 1 {
+			return nil, ErrInvalidActionArgs
+		} else if text, ok := stringifySingle(args[0]); !ok {
+			return nil, ErrInvalidActionArgs
+		} else {
+			set := func(context.Context, Trigger) error {
+				return DefaultClipboard().Set(text)
 			}
-			return open, nil
+			return set, nil
 		}
 	},
-	// os:cmd runs a custom command.
+	// clip:transform pipes the clipboard contents through a command, and writes
+	// its output back to the clipboard.
 	// Arguments:
 	// - cmd string: The command name or path.
 	// - cmdArgs ...string: List of extra arguments to pass to the command.
-	"os:cmd": func(args ...interface{}) (Action, error) {
+	"clip:transform": func(args ...interface{}) (Action, error) {
 		if len(args) < 1 {
 			return nil, ErrInvalidActionArgs
 		} else if cmdName, ok := stringifySingle(args[0]); !ok {
@@ -148,12 +403,34 @@ var actionCreators = map[string]ActionCreator{
 			if !ok {
 				return nil, ErrInvalidActionArgs
 			}
-			cmd := exec.Command(cmdName, cmdArgs...)
-			run := func() {
-				c := *cmd
-				c.Start()
+			transform := func(ctx context.Context, _ Trigger) error {
+				return DefaultClipboard().Transform(ctx, cmdName, cmdArgs...)
 			}
-			return run, nil
+			return transform, nil
+		}
+	},
+
+	// http:request sends an HTTP request.
+	// Arguments:
+	// - url string: The request URL.
+	// - options map: Optional options:
+	//   - method string: The request method (defaults to GET, or to POST with a
+	//     body).
+	//   - headers map: Additional request headers.
+	//   - body string: The request body.
+	//   - timeout int: The request timeout in milliseconds (defaults to 10s).
+	//   - fail-on-status bool: Whether to report non-2xx response statuses as
+	//     failures.
+	"http:request": func(args ...interface{}) (Action, error) {
+		args, optsArg := splitActionOptions(args)
+		if len(args) != 1 {
+			return nil, ErrInvalidActionArgs
+		} else if url, ok := stringifySingle(args[0]); !ok {
+			return nil, ErrInvalidActionArgs
+		} else if req, ok := parseHTTPRequest(url, optsArg); !ok {
+			return nil, ErrInvalidActionArgs
+		} else {
+			return NewHTTPRequest(req)
 		}
 	},
 
@@ -180,11 +457,33 @@ var actionCreators = map[string]ActionCreator{
 			return nil, ErrInvalidActionArgs
 		}
 		d := time.Duration(ms) * time.Millisecond
-		sleep := func() { time.Sleep(d) }
+		sleep := func(ctx context.Context, _ Trigger) error {
+			return sleepContext(ctx, d)
+		}
 		return sleep, nil
 	},
 }
 
+// newSystemKeyTap creates an action tapping a system key (e.g. a media key)
+// via robotgo.
+func newSystemKeyTap(key string) Action {
+	return func(context.Context, Trigger) error {
+		return robotgo.KeyTap(key)
+	}
+}
+
+// sleepContext pauses for duration d, or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func stringify(args []interface{}) ([]string, bool) {
 	strs := make([]string, len(args))
 	for i, arg := range args {
@@ -208,6 +507,63 @@ func stringifySingle(arg interface{}) (string, bool) {
 	}
 }
 
+func numberify(arg interface{}) (float64, bool) {
+	switch n := arg.(type) {
+	case int:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func parseKeyCombo(args []interface{}) (key string, modifiers []string, ok bool) {
+	l := len(args)
+	if l < 1 {
+		return "", nil, false
+	}
+	if key, ok = stringifySingle(args[l-1]); !ok || !isValidKeyName(key) {
+		return "", nil, false
+	}
+	if modifiers, ok = stringify(args[:l-1]); !ok {
+		return "", nil, false
+	}
+	for _, m := range modifiers {
+		if !isValidKeyName(m) {
+			return "", nil, false
+		}
+	}
+	return key, modifiers, true
+}
+
+func parseOptionalString(args []interface{}) (string, bool) {
+	switch len(args) {
+	case 0:
+		return "", true
+	case 1:
+		str, ok := args[0].(string)
+		return str, ok
+	default:
+		return "", false
+	}
+}
+
+func parsePointerArgs(args []interface{}) (x, y float64, mode string, ok bool) {
+	if x, ok = numberify(args[0]); !ok {
+		return
+	}
+	if y, ok = numberify(args[1]); !ok {
+		return
+	}
+	if len(args) > 2 {
+		mode, ok = args[2].(string)
+	}
+	return
+}
+
 func destringify(strs []string) []interface{} {
 	out := make([]interface{}, len(strs))
 	for i, str := range strs {
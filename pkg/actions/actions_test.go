@@ -1,11 +1,15 @@
 package actions_test
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/echocrow/Mouser/pkg/actions"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type i = interface{}
@@ -42,6 +46,28 @@ func TestNewAction(t *testing.T) {
 			{[]i{"f1", []i{}}, false},
 			{[]i{[]i{"f1"}}, false},
 			{[]i{"shift", "f1"}, true},
+			{[]i{"phys:z"}, true},
+			{[]i{"cmd", "sc:0x2c"}, true},
+			{[]i{"cmd", "sc:44"}, true},
+			{[]i{"cmd", "sc:0xff"}, false},
+			{[]i{"cmd", "sc:foo"}, false},
+			{[]i{"cmd", "phys:"}, false},
+			{[]i{"", "a"}, false},
+			{[]i{""}, false},
+		},
+
+		"io:key-down": {
+			{nil, false},
+			{[]i{}, false},
+			{[]i{"cmd"}, true},
+			{[]i{"cmd", "tab"}, true},
+			{[]i{"tab", []i{}}, false},
+		},
+		"io:key-up": {
+			{nil, false},
+			{[]i{"cmd"}, true},
+			{[]i{"cmd", "tab"}, true},
+			{[]i{[]i{"tab"}}, false},
 		},
 
 		"io:type": {
@@ -51,6 +77,14 @@ func TestNewAction(t *testing.T) {
 			{[]i{"foo"}, true},
 			{[]i{"foo", "bar"}, false},
 			{[]i{[]i{"foo"}}, false},
+			{[]i{"foo", map[string]i{}}, true},
+			{[]i{"{enter}", map[string]i{"keys": true, "char-delay": 5, "key-delay": 10}}, true},
+			{[]i{"{enter", map[string]i{"keys": true}}, false},
+			{[]i{"{enter", map[string]i{"keys": false}}, true},
+			{[]i{"foo", map[string]i{"keys": "yes"}}, false},
+			{[]i{"foo", map[string]i{"char-delay": -1}}, false},
+			{[]i{"foo", map[string]i{"foo": 1}}, false},
+			{[]i{map[string]i{"keys": true}}, false},
 		},
 
 		"io:scroll": {
@@ -67,7 +101,117 @@ func TestNewAction(t *testing.T) {
 			{[]i{1, 1, 1}, false},
 		},
 
+		"io:click": {
+			{nil, true},
+			{[]i{}, true},
+			{[]i{"left"}, true},
+			{[]i{"right", 2}, true},
+			{[]i{"middle", 3}, true},
+			{[]i{"foo"}, false},
+			{[]i{1}, false},
+			{[]i{"left", 0}, false},
+			{[]i{"left", "2"}, false},
+			{[]i{"left", 2, 1}, false},
+		},
+
+		"io:move": {
+			{nil, false},
+			{[]i{}, false},
+			{[]i{1}, false},
+			{[]i{1, 2}, true},
+			{[]i{1.5, uint(2)}, true},
+			{[]i{-1, 2}, false},
+			{[]i{-1, 2, "rel"}, true},
+			{[]i{50, 50, "pct"}, true},
+			{[]i{50, 101, "pct"}, false},
+			{[]i{1, 2, "abs"}, true},
+			{[]i{1, 2, "foo"}, false},
+			{[]i{1, 2, 3}, false},
+			{[]i{"1", "2"}, false},
+			{[]i{1, 2, "abs", "left"}, false},
+		},
+
+		"io:drag": {
+			{nil, false},
+			{[]i{1}, false},
+			{[]i{1, 2}, true},
+			{[]i{1, 2, "rel"}, true},
+			{[]i{1, 2, "rel", "right"}, true},
+			{[]i{1, 2, "rel", "foo"}, false},
+			{[]i{1, 2, "foo"}, false},
+			{[]i{1, 2, "abs", "left", 1}, false},
+		},
+
+		"io:mouse-down": {
+			{nil, true},
+			{[]i{"right"}, true},
+			{[]i{"foo"}, false},
+			{[]i{1}, false},
+			{[]i{"left", "right"}, false},
+		},
+		"io:mouse-up": {
+			{nil, true},
+			{[]i{"right"}, true},
+			{[]i{"foo"}, false},
+		},
+
 		"os:open": {
+			{nil, false},
+			{[]i{}, false},
+			{[]i{1}, true},
+			{[]i{"foo"}, true},
+			// Only the macOS opener takes extra arguments.
+			{[]i{"foo", "-v"}, runtime.GOOS == "darwin"},
+			{[]i{"foo", 1}, runtime.GOOS == "darwin"},
+			{[]i{"foo", []i{"-v"}}, false},
+			{[]i{""}, false},
+			{[]i{"https://example.com", map[string]i{"app": "firefox"}}, true},
+			{[]i{"foo", map[string]i{"app": 1}}, true},
+			{[]i{"foo", map[string]i{"app-args": []i{"-v", 1}}}, true},
+			{[]i{"foo", map[string]i{"app-args": "-v"}}, false},
+			{[]i{"foo", map[string]i{"app-args": []i{[]i{"-v"}}}}, false},
+			{[]i{"foo", map[string]i{"bar": "baz"}}, false},
+			{[]i{map[string]i{"app": "firefox"}}, false},
+		},
+
+		"ui:notify": {
+			{nil, false},
+			{[]i{"foo"}, false},
+			{[]i{"foo", "bar"}, true},
+			{[]i{"foo", 1}, true},
+			{[]i{"foo", "bar", "critical"}, true},
+			{[]i{"foo", "bar", "foo"}, false},
+			{[]i{"foo", "bar", "low", "baz"}, false},
+		},
+
+		"var:set": {
+			{nil, false},
+			{[]i{"foo"}, false},
+			{[]i{"foo", "bar"}, true},
+			{[]i{"foo", 1}, true},
+			{[]i{"foo", true}, true},
+			{[]i{"", "bar"}, false},
+			{[]i{1, "bar"}, false},
+			{[]i{"foo", []i{"bar"}}, false},
+			{[]i{"foo", "bar", "baz"}, false},
+		},
+		"var:inc": {
+			{nil, false},
+			{[]i{"foo"}, true},
+			{[]i{"foo", -2}, true},
+			{[]i{"foo", 1.5}, false},
+			{[]i{""}, false},
+			{[]i{"foo", 1, 2}, false},
+		},
+		"var:toggle": {
+			{nil, false},
+			{[]i{"foo"}, true},
+			{[]i{""}, false},
+			{[]i{1}, false},
+			{[]i{"foo", "bar"}, false},
+		},
+
+		"os:cmd": {
 			{nil, false},
 			{[]i{}, false},
 			{[]i{1}, true},
@@ -75,18 +219,70 @@ func TestNewAction(t *testing.T) {
 			{[]i{"foo", "-v"}, true},
 			{[]i{"foo", 1}, true},
 			{[]i{"foo", []i{"-v"}}, false},
+			{[]i{"foo", map[string]i{}}, true},
+			{[]i{"echo $1", "foo", map[string]i{
+				"shell":   true,
+				"env":     map[string]i{"FOO": "bar", "BAR": 1},
+				"cwd":     "/tmp",
+				"stdin":   "foo",
+				"timeout": 1000,
+				"wait":    true,
+				"output":  "clipboard",
+			}}, true},
+			{[]i{"echo", map[string]i{"shell": "bash"}}, true},
+			{[]i{"echo", map[string]i{"shell": 1}}, false},
+			{[]i{"echo", map[string]i{"env": []i{"FOO"}}}, false},
+			{[]i{"echo", map[string]i{"timeout": "1"}}, false},
+			{[]i{"echo", map[string]i{"wait": "yes"}}, false},
+			{[]i{"echo", map[string]i{"output": "foo"}}, false},
+			{[]i{"echo", map[string]i{"policy": "restart"}}, true},
+			{[]i{"echo", map[string]i{"policy": "foo"}}, false},
+			{[]i{"echo", map[string]i{"foo": "bar"}}, false},
+			{[]i{map[string]i{}}, false},
 		},
 
-		"os:cmd": {
+		"clip:copy-selection": {{nil, true}, {[]i{"foo"}, false}},
+		"clip:paste":          {{nil, true}, {[]i{"foo"}, false}},
+		"clip:paste-prev":     {{nil, true}, {[]i{"foo"}, false}},
+
+		"clip:set": {
 			{nil, false},
 			{[]i{}, false},
 			{[]i{1}, true},
 			{[]i{"foo"}, true},
+			{[]i{"foo", "bar"}, false},
+			{[]i{[]i{"foo"}}, false},
+		},
+
+		"clip:transform": {
+			{nil, false},
+			{[]i{}, false},
+			{[]i{"foo"}, true},
 			{[]i{"foo", "-v"}, true},
 			{[]i{"foo", 1}, true},
 			{[]i{"foo", []i{"-v"}}, false},
 		},
 
+		"http:request": {
+			{nil, false},
+			{[]i{}, false},
+			{[]i{"http://localhost"}, true},
+			{[]i{"http://localhost", map[string]i{}}, true},
+			{[]i{"http://localhost", map[string]i{
+				"method":         "post",
+				"headers":        map[string]i{"X-Foo": "bar", "X-Bar": 1},
+				"body":           "foo",
+				"timeout":        1000,
+				"fail-on-status": true,
+			}}, true},
+			{[]i{"http://localhost", map[string]i{"method": "GET POST"}}, false},
+			{[]i{"http://localhost", map[string]i{"headers": "foo"}}, false},
+			{[]i{"http://localhost", map[string]i{"timeout": -1}}, false},
+			{[]i{"http://localhost", map[string]i{"foo": "bar"}}, false},
+			{[]i{"http://localhost", "foo"}, false},
+			{[]i{map[string]i{}}, false},
+		},
+
 		"misc:sleep": {
 			{nil, false},
 			{[]i{}, false},
@@ -116,3 +312,15 @@ func TestNewAction(t *testing.T) {
 		}
 	}
 }
+
+func TestSleepCancel(t *testing.T) {
+	t.Parallel()
+
+	a, err := actions.New("misc:sleep", 5000)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, a(ctx, actions.Trigger{}), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -1,70 +1,204 @@
 package actions
 
 import (
+	"context"
 	"os"
-
-	"github.com/go-vgo/robotgo"
+	"regexp"
+	"sort"
+	"strings"
 )
 
+// TitleMatcher describes a window title predicate.
+type TitleMatcher func(title string) bool
+
+// NewTitleMatcher creates a window title matcher that matches titles
+// containing substr.
+func NewTitleMatcher(substr string) TitleMatcher {
+	return func(title string) bool {
+		return strings.Contains(title, substr)
+	}
+}
+
+// NewTitleRegexpMatcher creates a window title matcher that matches titles
+// against the regular expression expr.
+func NewTitleRegexpMatcher(expr string) (TitleMatcher, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// WindowBranch holds a foreground-window-specific action branch.
+//
+// App holds an app pattern (see NewAppMatcher). An empty App matches any app.
+// A nil Title matches any window title.
+type WindowBranch struct {
+	App    string
+	Title  TitleMatcher
+	Action Action
+}
+
 // NewAppBranch creates an app-based actions branch.
+//
+// Branches are keyed by app pattern (see NewAppMatcher). Window branches are
+// checked in order and take precedence over app branches. The foreground app
+// is detected via fp, or via DefaultForeground when fp is nil.
 func NewAppBranch(
 	branches map[string]Action,
+	windows []WindowBranch,
 	fallback Action,
-) Action {
+	fp ForegroundProvider,
+) (Action, error) {
+	if fp == nil {
+		fp = DefaultForeground()
+	}
 	return NewAppBranchCustom(
 		branches,
+		windows,
 		fallback,
-		getForegroundAppPath,
+		fp,
 		os.PathSeparator,
 	)
 }
 
 // NewAppBranchCustom creates an app-based actions branch based on a custom
-// app-detection callback.
+// path separator.
 func NewAppBranchCustom(
 	branches map[string]Action,
+	windows []WindowBranch,
 	fallback Action,
-	getApp func() string,
+	fp ForegroundProvider,
 	pathSeparator rune,
-) Action {
-	return func() {
-		app := getApp()
-		action := getAppMatch(branches, fallback, app, pathSeparator)
-		if action != nil {
-			action()
+) (Action, error) {
+	ws, err := newWindowMatchers(windows, pathSeparator)
+	if err != nil {
+		return nil, err
+	}
+	exact, matchers, err := newAppMatchers(branches, pathSeparator)
+	if err != nil {
+		return nil, err
+	}
+	cache := newActionCache(appBranchCacheCap)
+	a := func(ctx context.Context, ev Trigger) error {
+		app := foregroundApp(ctx, fp)
+		action, ok := getWindowMatch(ws, app)
+		if !ok {
+			action = getAppMatch(exact, matchers, cache, fallback, app.Path)
 		}
+		return runAction(ctx, action, ev)
 	}
+	return a, nil
 }
 
-func getAppMatch(
+type windowMatcher struct {
+	app    AppMatcher
+	title  TitleMatcher
+	action Action
+}
+
+func newWindowMatchers(
+	windows []WindowBranch,
+	pathSeparator rune,
+) ([]windowMatcher, error) {
+	ws := make([]windowMatcher, len(windows))
+	for i, w := range windows {
+		ws[i] = windowMatcher{title: w.Title, action: w.Action}
+		if w.App != "" {
+			match, err := NewAppMatcher(w.App, pathSeparator)
+			if err != nil {
+				return nil, err
+			}
+			ws[i].app = match
+		}
+	}
+	return ws, nil
+}
+
+func getWindowMatch(
+	windows []windowMatcher,
+	app ForegroundApp,
+) (Action, bool) {
+	for _, w := range windows {
+		if w.app != nil && !w.app(app.Path) {
+			continue
+		}
+		if w.title != nil && !w.title(app.Title) {
+			continue
+		}
+		return w.action, true
+	}
+	return nil, false
+}
+
+// appBranchCacheCap is the max number of app paths to remember per app branch.
+const appBranchCacheCap = 64
+
+type appMatcher struct {
+	match  AppMatcher
+	action Action
+}
+
+// newAppMatchers compiles app branches into exact app path matches and an
+// ordered list of app pattern matchers, with longer patterns first.
+func newAppMatchers(
 	branches map[string]Action,
+	pathSeparator rune,
+) (map[string]Action, []appMatcher, error) {
+	exact := make(map[string]Action, len(branches))
+	patterns := make([]string, 0, len(branches))
+	for pattern, action := range branches {
+		if !isTypedAppPattern(pattern) {
+			exact[pattern] = action
+		}
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		a, b := patterns[i], patterns[j]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+
+	matchers := make([]appMatcher, len(patterns))
+	for i, pattern := range patterns {
+		match, err := NewAppMatcher(pattern, pathSeparator)
+		if err != nil {
+			return nil, nil, err
+		}
+		matchers[i] = appMatcher{match, branches[pattern]}
+	}
+	return exact, matchers, nil
+}
+
+func getAppMatch(
+	exact map[string]Action,
+	matchers []appMatcher,
+	cache *actionCache,
 	fallback Action,
 	app string,
-	pathSeparator rune,
 ) Action {
-	if action, ok := branches[app]; ok {
+	if action, ok := exact[app]; ok {
 		return action
 	}
 
-	if app != "" {
-		pathSep := string(pathSeparator)
-		for appPrefix := range branches {
-			if matchAppPathPrefix(app, appPrefix, pathSep) {
-				// Remember action assocaition for next time.
-				action := branches[appPrefix]
-				branches[app] = action
-				return action
-			}
-		}
+	if app == "" {
+		return fallback
 	}
 
-	return fallback
-}
+	if action, ok := cache.get(app); ok {
+		return action
+	}
 
-func getForegroundAppPath() string {
-	pid := int32(robotgo.GetPid())
-	if pid == 0 {
-		return ""
+	action := fallback
+	for _, m := range matchers {
+		if m.match(app) {
+			action = m.action
+			break
+		}
 	}
-	return getPidPath(pid)
+	// Remember action assocaition for next time.
+	cache.set(app, action)
+	return action
 }
@@ -1,6 +1,7 @@
 package actions_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/echocrow/Mouser/pkg/actions"
@@ -9,34 +10,49 @@ import (
 
 type act = actions.Action
 
+// newTestAction creates an action calling f.
+func newTestAction(f func()) act {
+	return func(context.Context, actions.Trigger) error {
+		f()
+		return nil
+	}
+}
+
 func TestNewAppBranchCustom(t *testing.T) {
 	const (
 		app1     = "app_1"
 		app2     = "app_2"
 		emptyApp = ""
+		namedApp = "named_app"
+		globApp  = "glob_app"
+		reApp    = "re_app"
 		fallback = "fallback"
 	)
 
 	calls := make(chan string, 2)
 
 	branches := map[string]act{
-		"known_app_1": func() { calls <- app1 },
-		"known_app_2": func() { calls <- app2 },
-		"nil_app":     nil,
-		"":            func() { calls <- emptyApp },
+		"known_app_1":     newTestAction(func() { calls <- app1 }),
+		"known_app_2":     newTestAction(func() { calls <- app2 }),
+		"nil_app":         nil,
+		"":                newTestAction(func() { calls <- emptyApp }),
+		"name:named_app":  newTestAction(func() { calls <- namedApp }),
+		"glob:glob_*_app": newTestAction(func() { calls <- globApp }),
+		"re:^re_app_\\d$": newTestAction(func() { calls <- reApp }),
 	}
-	fallbackAction := func() { calls <- fallback }
+	fallbackAction := newTestAction(func() { calls <- fallback })
 
-	currApp := ""
-	getApp := func() string { return currApp }
-	setApp := func(app string) { currApp = app }
+	fg := new(actions.FakeForeground)
+	setApp := func(app string) { fg.Set(actions.ForegroundApp{Path: app}) }
 
-	branchAction := actions.NewAppBranchCustom(
+	branchAction, err := actions.NewAppBranchCustom(
 		branches,
+		nil,
 		fallbackAction,
-		getApp,
+		fg,
 		'/',
 	)
+	assert.NoError(t, err)
 
 	tests := []struct {
 		name     string
@@ -53,6 +69,10 @@ func TestNewAppBranchCustom(t *testing.T) {
 		{"calls app action from path suffix", "known_app_1/suffix", app1, true},
 		{"calls fallback from non-path suffix", "known_app_1suffix", fallback, true},
 		{"calls fallback from extra prefix", "prefix/known_app_1", fallback, true},
+		{"calls app action by name", "prefix/named_app", namedApp, true},
+		{"calls app action by glob", "glob_foo_app/suffix", globApp, true},
+		{"calls app action by regexp", "re_app_1", reApp, true},
+		{"calls fallback from regexp mismatch", "re_app_12", fallback, true},
 	}
 	for _, tc := range tests {
 		tc := tc
@@ -63,6 +83,87 @@ func TestNewAppBranchCustom(t *testing.T) {
 	}
 }
 
+func TestNewAppBranchCustomWindows(t *testing.T) {
+	const (
+		app1Title1 = "app_1_title_1"
+		app1Title2 = "app_1_title_2"
+		anyTitle3  = "any_title_3"
+		app1       = "app_1"
+		fallback   = "fallback"
+	)
+
+	calls := make(chan string, 2)
+
+	titleRe, err := actions.NewTitleRegexpMatcher(`^Title #\d+$`)
+	assert.NoError(t, err)
+
+	windows := []actions.WindowBranch{
+		{
+			App:    "known_app_1",
+			Title:  actions.NewTitleMatcher("Title 1"),
+			Action: newTestAction(func() { calls <- app1Title1 }),
+		},
+		{
+			App:    "known_app_1",
+			Title:  titleRe,
+			Action: newTestAction(func() { calls <- app1Title2 }),
+		},
+		{
+			Title:  actions.NewTitleMatcher("Title 3"),
+			Action: newTestAction(func() { calls <- anyTitle3 }),
+		},
+		{
+			Title:  actions.NewTitleMatcher("Nil Title"),
+			Action: nil,
+		},
+	}
+	branches := map[string]act{
+		"known_app_1": newTestAction(func() { calls <- app1 }),
+	}
+	fallbackAction := newTestAction(func() { calls <- fallback })
+
+	fg := new(actions.FakeForeground)
+
+	branchAction, err := actions.NewAppBranchCustom(
+		branches,
+		windows,
+		fallbackAction,
+		fg,
+		'/',
+	)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		title    string
+		wantCall string
+		wantOk   bool
+	}{
+		{"calls title substring action", "known_app_1", "My Title 1", app1Title1, true},
+		{"calls title regexp action", "known_app_1", "Title #42", app1Title2, true},
+		{"calls title action from path suffix", "known_app_1/suffix", "Title #42", app1Title2, true},
+		{"calls any-app title action", "unknown_app", "Title 3", anyTitle3, true},
+		{"prefers earlier window branch", "known_app_1", "Title 1 Title 3", app1Title1, true},
+		{"does not call nil window action", "known_app_1", "Nil Title", "", false},
+		{"calls app action on other title", "known_app_1", "Title #42 and more", app1, true},
+		{"calls fallback on other app", "unknown_app", "Title 1", fallback, true},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			fg.Set(actions.ForegroundApp{Path: tc.path, Title: tc.title})
+			assertActionCalls(t, branchAction, tc.wantOk, tc.wantCall, calls)
+		})
+	}
+}
+
+func TestNewTitleRegexpMatcher(t *testing.T) {
+	t.Parallel()
+	_, err := actions.NewTitleRegexpMatcher(`(`)
+	assert.Error(t, err)
+}
+
 func assertActionCalls(
 	t *testing.T,
 	action actions.Action,
@@ -72,7 +173,7 @@ func assertActionCalls(
 ) {
 	assertNoCall(t, calls, "premature")
 
-	action()
+	action(context.Background(), actions.Trigger{})
 
 	if wantOk {
 		assertCall(t, calls, wantCall)
@@ -0,0 +1,235 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// AppMatcher describes an app path predicate.
+type AppMatcher func(path string) bool
+
+// App pattern type prefixes.
+const (
+	appPatternName   = "name:"
+	appPatternBundle = "bundle:"
+	appPatternGlob   = "glob:"
+	appPatternRegexp = "re:"
+)
+
+const (
+	appBundleExt         = ".app"
+	appBundleInfoPath    = "Contents/Info.plist"
+	appBundleIDKey       = "CFBundleIdentifier"
+	binaryPlistSignature = "bplist"
+)
+
+// App matcher errors raised by package actions.
+var (
+	ErrInvalidAppPattern = errors.New("app pattern is invalid")
+)
+
+// NewAppMatcher creates an app matcher from a (typed) app pattern.
+//
+// Supported patterns are:
+//   - "name:<name>": matches the executable or app bundle name
+//     (case-insensitive, without file extension).
+//   - "bundle:<id>": matches the app bundle identifier (macOS only).
+//   - "glob:<glob>": matches the app path or any of its parent directories via
+//     a shell file name pattern.
+//   - "re:<regexp>": matches the app path via a regular expression.
+//   - "<path>": matches the app path or any of its parent directories.
+func NewAppMatcher(pattern string, pathSeparator rune) (AppMatcher, error) {
+	pathSep := string(pathSeparator)
+	switch {
+	case strings.HasPrefix(pattern, appPatternName):
+		name := strings.TrimPrefix(pattern, appPatternName)
+		if name == "" {
+			return nil, ErrInvalidAppPattern
+		}
+		return func(path string) bool {
+			return matchAppName(path, name, pathSep)
+		}, nil
+	case strings.HasPrefix(pattern, appPatternBundle):
+		id := strings.TrimPrefix(pattern, appPatternBundle)
+		if id == "" {
+			return nil, ErrInvalidAppPattern
+		}
+		return func(path string) bool {
+			return getBundleID(path, pathSep) == id
+		}, nil
+	case strings.HasPrefix(pattern, appPatternGlob):
+		glob := strings.TrimPrefix(pattern, appPatternGlob)
+		if _, err := filepath.Match(glob, ""); err != nil || glob == "" {
+			return nil, ErrInvalidAppPattern
+		}
+		return func(path string) bool {
+			return matchAppPathGlob(path, glob, pathSep)
+		}, nil
+	case strings.HasPrefix(pattern, appPatternRegexp):
+		re, err := regexp.Compile(strings.TrimPrefix(pattern, appPatternRegexp))
+		if err != nil {
+			return nil, err
+		}
+		return func(path string) bool {
+			return path != "" && re.MatchString(path)
+		}, nil
+	default:
+		return func(path string) bool {
+			return matchAppPathPrefix(path, pattern, pathSep)
+		}, nil
+	}
+}
+
+func isTypedAppPattern(pattern string) bool {
+	for _, prefix := range []string{
+		appPatternName,
+		appPatternBundle,
+		appPatternGlob,
+		appPatternRegexp,
+	} {
+		if strings.HasPrefix(pattern, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAppPathPrefix(path, name, pathSep string) bool {
+	return name != "" && (path == name || strings.HasPrefix(path, name+pathSep))
+}
+
+func matchAppPathGlob(path, glob, pathSep string) bool {
+	for p := path; p != ""; {
+		if ok, _ := filepath.Match(glob, p); ok {
+			return true
+		}
+		i := strings.LastIndex(p, pathSep)
+		if i <= 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return false
+}
+
+func matchAppName(path, name, pathSep string) bool {
+	if path == "" {
+		return false
+	}
+	exe := path[strings.LastIndex(path, pathSep)+1:]
+	if strings.EqualFold(trimExt(exe), name) {
+		return true
+	}
+	if bundle := getBundlePath(path, pathSep); bundle != "" {
+		bundleName := bundle[strings.LastIndex(bundle, pathSep)+1:]
+		return strings.EqualFold(trimExt(bundleName), name)
+	}
+	return false
+}
+
+func trimExt(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// getBundlePath gets the path of the outermost app bundle containing path.
+func getBundlePath(path, pathSep string) string {
+	parts := strings.Split(path, pathSep)
+	for i, part := range parts {
+		if strings.HasSuffix(part, appBundleExt) {
+			return strings.Join(parts[:i+1], pathSep)
+		}
+	}
+	return ""
+}
+
+// bundleIDsCap is the max number of app bundle identifiers to remember.
+const bundleIDsCap = 256
+
+// bundleIDs caches the identifiers of app bundles by bundle path; random
+// entries are evicted once full.
+var (
+	bundleIDs   = make(map[string]string)
+	bundleIDsMx sync.Mutex
+)
+
+// getBundleID gets the identifier of the outermost app bundle containing path.
+func getBundleID(path, pathSep string) string {
+	bundle := getBundlePath(path, pathSep)
+	if bundle == "" {
+		return ""
+	}
+	bundleIDsMx.Lock()
+	id, ok := bundleIDs[bundle]
+	bundleIDsMx.Unlock()
+	if ok {
+		return id
+	}
+	id = readBundleID(bundle + pathSep + appBundleInfoPath)
+	bundleIDsMx.Lock()
+	defer bundleIDsMx.Unlock()
+	for b := range bundleIDs {
+		if len(bundleIDs) < bundleIDsCap {
+			break
+		}
+		delete(bundleIDs, b)
+	}
+	bundleIDs[bundle] = id
+	return id
+}
+
+func readBundleID(plistPath string) string {
+	plist, err := os.ReadFile(plistPath)
+	if err != nil {
+		return ""
+	}
+	if bytes.HasPrefix(plist, []byte(binaryPlistSignature)) {
+		cmd := exec.Command("plutil", "-convert", "xml1", "-o", "-", plistPath)
+		if plist, err = cmd.Output(); err != nil {
+			return ""
+		}
+	}
+	return parsePlistString(plist, appBundleIDKey)
+}
+
+// parsePlistString looks up a top-level string value in an XML property list.
+func parsePlistString(plist []byte, key string) string {
+	d := xml.NewDecoder(bytes.NewReader(plist))
+	depth := 0
+	isKey := false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return ""
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			// Top-level entries live at <plist><dict><key/>.
+			if depth != 3 {
+				continue
+			}
+			var val string
+			if t.Name.Local == "key" {
+				if err := d.DecodeElement(&val, &t); err != nil {
+					return ""
+				}
+				depth--
+				isKey = val == key
+			} else if isKey {
+				if t.Name.Local == "string" && d.DecodeElement(&val, &t) == nil {
+					return val
+				}
+				return ""
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
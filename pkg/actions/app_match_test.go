@@ -0,0 +1,115 @@
+package actions_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleExecutable</key>
+	<string>Foo</string>
+	<key>CFBundleDocumentTypes</key>
+	<array>
+		<dict>
+			<key>CFBundleIdentifier</key>
+			<string>com.example.nested</string>
+		</dict>
+	</array>
+	<key>CFBundleIdentifier</key>
+	<string>com.example.foo</string>
+</dict>
+</plist>
+`
+
+func TestNewAppMatcher(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "Foo.app")
+	require.NoError(t, os.MkdirAll(filepath.Join(bundle, "Contents"), 0700))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(bundle, "Contents", "Info.plist"),
+		[]byte(testInfoPlist),
+		0600,
+	))
+	bundleExe := filepath.Join(bundle, "Contents", "MacOS", "foo")
+
+	tests := []struct {
+		pattern string
+		paths   map[string]bool
+		wantOk  bool
+	}{
+		{"/opt/foo", map[string]bool{
+			"/opt/foo":     true,
+			"/opt/foo/bar": true,
+			"/opt/foobar":  false,
+			"/opt":         false,
+			"":             false,
+		}, true},
+		{"", map[string]bool{
+			"":     false,
+			"/foo": false,
+		}, true},
+		{"name:firefox", map[string]bool{
+			"/usr/lib/firefox/firefox":                   true,
+			"/usr/lib/firefox/Firefox.exe":               true,
+			"/Applications/Firefox.app":                  true,
+			"/Applications/Firefox.app/Contents/MacOS/x": true,
+			"/usr/lib/firefox/firefox-bin":               false,
+			"/usr/bin/foo":                               false,
+			"":                                           false,
+		}, true},
+		{"name:", nil, false},
+		{"bundle:com.example.foo", map[string]bool{
+			bundle:                        true,
+			bundleExe:                     true,
+			filepath.Join(dir, "Bar.app"): false,
+			dir:                           false,
+			"":                            false,
+		}, true},
+		{"bundle:com.example.nested", map[string]bool{
+			bundle: false,
+		}, true},
+		{"bundle:", nil, false},
+		{"glob:/opt/*/code", map[string]bool{
+			"/opt/vscode/code":          true,
+			"/opt/vscode/code/bin/code": true,
+			"/opt/code":                 false,
+			"/opt/vscode/codium":        false,
+			"":                          false,
+		}, true},
+		{"glob:", nil, false},
+		{"glob:[", nil, false},
+		{"re:^/opt/.+/code$", map[string]bool{
+			"/opt/vscode/code":     true,
+			"/opt/vs/code/code":    true,
+			"/opt/vscode/code/bin": false,
+			"":                     false,
+		}, true},
+		{"re:(", nil, false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.pattern, func(t *testing.T) {
+			t.Parallel()
+			match, err := actions.NewAppMatcher(tc.pattern, '/')
+			if !tc.wantOk {
+				assert.Nil(t, match)
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for path, want := range tc.paths {
+				assert.Equal(t, want, match(path), "path \"%s\"", path)
+			}
+		})
+	}
+}
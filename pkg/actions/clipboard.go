@@ -0,0 +1,220 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Clipboard settings.
+const (
+	defaultClipHistorySize = 16
+	clipCopyTimeout        = time.Millisecond * 500
+	clipCopyPollRate       = time.Millisecond * 10
+	clipTransformTimeout   = time.Second * 10
+)
+
+// Clipboard errors raised by package actions.
+var (
+	ErrClipHistoryEmpty = errors.New("clipboard history has no previous entry")
+)
+
+// Clipboard provides clipboard actions with a clipboard history ring.
+//
+// The history records the distinct clipboard contents seen before and after
+// every clipboard action.
+type Clipboard struct {
+	drv     Driver
+	clock   Clock
+	size    int
+	history []string
+	cursor  int
+	mx      sync.Mutex
+}
+
+// NewClipboard creates a new clipboard based on a custom driver.
+//
+// When drv is nil, the default driver is used. The history keeps up to size
+// entries.
+func NewClipboard(drv Driver, size int) *Clipboard {
+	return NewClipboardCustom(drv, size, SystemClock{})
+}
+
+// NewClipboardCustom creates a new clipboard based on a custom driver and
+// clock.
+//
+// When drv is nil, the default driver is used. When clock is nil, the system
+// clock is used.
+func NewClipboardCustom(drv Driver, size int, clock Clock) *Clipboard {
+	if drv == nil {
+		drv = DefaultDriver()
+	}
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	if size < 1 {
+		size = 1
+	}
+	return &Clipboard{drv: drv, clock: clock, size: size}
+}
+
+var (
+	sharedClipboard     *Clipboard
+	sharedClipboardOnce sync.Once
+)
+
+// DefaultClipboard gets the shared default clipboard.
+func DefaultClipboard() *Clipboard {
+	sharedClipboardOnce.Do(func() {
+		sharedClipboard = NewClipboard(nil, defaultClipHistorySize)
+	})
+	return sharedClipboard
+}
+
+// Set writes text to the clipboard.
+func (c *Clipboard) Set(text string) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.write(text)
+}
+
+// CopySelection copies the current selection to the clipboard.
+func (c *Clipboard) CopySelection() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	prev := c.read()
+	c.drv.KeyTap("c", shortcutModifier)
+
+	// Copying is asynchronous in most apps; await the clipboard change.
+	text := prev
+	for deadline := c.clock.Now().Add(clipCopyTimeout); ; {
+		if t, err := c.drv.ReadClipboard(); err == nil && t != prev {
+			text = t
+			break
+		}
+		if c.clock.Now().After(deadline) {
+			break
+		}
+		tick := make(chan struct{})
+		c.clock.AfterFunc(clipCopyPollRate, func() { close(tick) })
+		<-tick
+	}
+	c.remember(text)
+	return nil
+}
+
+// Paste pastes the current clipboard contents.
+func (c *Clipboard) Paste() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.read()
+	c.drv.KeyTap("v", shortcutModifier)
+	return nil
+}
+
+// Transform pipes the clipboard contents through a command, and writes its
+// output back to the clipboard.
+//
+// A single trailing newline appended by the command is dropped. The command is
+// killed once ctx is done, or after a timeout. The clipboard is not locked
+// while the command runs.
+func (c *Clipboard) Transform(ctx context.Context, name string, args ...string) error {
+	c.mx.Lock()
+	text := c.read()
+	c.mx.Unlock()
+
+	out, err := Cmd{
+		Name:    name,
+		Args:    args,
+		Stdin:   text,
+		Timeout: clipTransformTimeout,
+		Policy:  PolicyParallel,
+		Output:  CmdOutputClipboard,
+	}.Run(ctx)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(text, "\n") {
+		out = strings.TrimSuffix(out, "\n")
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.write(out)
+}
+
+// PastePrev writes the previous clipboard history entry to the clipboard and
+// pastes it.
+//
+// Consecutive calls step further back through the history, wrapping around
+// after the oldest entry.
+func (c *Clipboard) PastePrev() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	cur, err := c.drv.ReadClipboard()
+	if err != nil {
+		return err
+	}
+	if len(c.history) == 0 || cur != c.history[c.cursor] {
+		// The clipboard changed since; restart from its latest contents.
+		c.remember(cur)
+		c.cursor = len(c.history) - 1
+	}
+	if len(c.history) < 2 {
+		return ErrClipHistoryEmpty
+	}
+	c.cursor = (c.cursor + len(c.history) - 1) % len(c.history)
+	if err := c.drv.WriteClipboard(c.history[c.cursor]); err != nil {
+		return err
+	}
+	c.drv.KeyTap("v", shortcutModifier)
+	return nil
+}
+
+// History gets the clipboard history, from oldest to latest.
+func (c *Clipboard) History() []string {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return append([]string{}, c.history...)
+}
+
+// read reads and remembers the current clipboard contents.
+func (c *Clipboard) read() string {
+	text, err := c.drv.ReadClipboard()
+	if err != nil {
+		return ""
+	}
+	c.remember(text)
+	return text
+}
+
+// write remembers the current clipboard contents, and then writes and
+// remembers text.
+func (c *Clipboard) write(text string) error {
+	c.read()
+	if err := c.drv.WriteClipboard(text); err != nil {
+		return err
+	}
+	c.remember(text)
+	return nil
+}
+
+// remember moves text to the end of the history, unless it is empty.
+func (c *Clipboard) remember(text string) {
+	if text == "" {
+		return
+	}
+	for i, entry := range c.history {
+		if entry == text {
+			c.history = append(c.history[:i], c.history[i+1:]...)
+			break
+		}
+	}
+	if l := len(c.history); l >= c.size {
+		c.history = append(c.history[:0], c.history[l-c.size+1:]...)
+	}
+	c.history = append(c.history, text)
+	c.cursor = len(c.history) - 1
+}
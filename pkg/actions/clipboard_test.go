@@ -0,0 +1,172 @@
+package actions_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/echocrow/Mouser/pkg/actions/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClipboardSetAndPaste(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	drv.WriteClipboard("old")
+	c := actions.NewClipboard(drv, 8)
+
+	require.NoError(t, c.Set("foo"))
+	got, _ := drv.ReadClipboard()
+	assert.Equal(t, "foo", got)
+
+	require.NoError(t, c.Paste())
+	assert.Equal(t, []string{"foo"}, drv.Pasted())
+	assert.Equal(t, []string{"old", "foo"}, c.History())
+}
+
+func TestClipboardCopySelection(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	c := actions.NewClipboard(drv, 8)
+
+	drv.SetSelection("foo")
+	require.NoError(t, c.CopySelection())
+	got, _ := drv.ReadClipboard()
+	assert.Equal(t, "foo", got)
+	assert.Equal(t, []string{"foo"}, c.History())
+}
+
+func TestClipboardCopySelectionTimeout(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	drv.WriteClipboard("old")
+	clk := actions.NewFakeClock(time.Now())
+	c := actions.NewClipboardCustom(drv, 8, clk)
+
+	// Nothing is selected, so copying awaits a clipboard change until timing out.
+	done := make(chan error)
+	go func() { done <- c.CopySelection() }()
+	var err error
+	require.Eventually(t, func() bool {
+		clk.Advance(time.Second)
+		select {
+		case err = <-done:
+			return true
+		default:
+			return false
+		}
+	}, 200*time.Millisecond, 5*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old"}, c.History())
+}
+
+func TestClipboardTransform(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		text   string
+		cmd    []string
+		want   string
+		wantOk bool
+	}{
+		{"transforms text", "foo bar", []string{"tr", "a-z", "A-Z"}, "FOO BAR", true},
+		{"drops appended newline", "foo", []string{"sed", "s/o/0/g"}, "f00", true},
+		{"keeps existing newline", "foo\n", []string{"cat"}, "foo\n", true},
+		{"keeps empty output", "foo", []string{"true"}, "", true},
+		{"fails on failing command", "foo", []string{"false"}, "foo", false},
+		{"fails on missing command", "foo", []string{"mouser-missing-cmd"}, "foo", false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			drv := new(actions.FakeDriver)
+			drv.WriteClipboard(tc.text)
+			c := actions.NewClipboard(drv, 8)
+			err := c.Transform(context.Background(), tc.cmd[0], tc.cmd[1:]...)
+			if tc.wantOk {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+			got, _ := drv.ReadClipboard()
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestClipboardTransformUnlocked(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	drv.WriteClipboard("foo")
+	c := actions.NewClipboard(drv, 8)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.Transform(ctx, "sh", "-c", "sleep 5 & wait") }()
+
+	// The clipboard remains usable while the command runs.
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, c.Set("bar"))
+
+	start := time.Now()
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	got, _ := drv.ReadClipboard()
+	assert.Equal(t, "bar", got)
+}
+
+func TestClipboardPastePrev(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	c := actions.NewClipboard(drv, 3)
+
+	assert.ErrorIs(t, c.PastePrev(), actions.ErrClipHistoryEmpty)
+
+	for _, text := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, c.Set(text))
+	}
+	assert.Equal(t, []string{"b", "c", "d"}, c.History())
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, c.PastePrev())
+	}
+	assert.Equal(t, []string{"c", "b", "d", "c"}, drv.Pasted())
+	assert.Equal(t, []string{"b", "c", "d"}, c.History())
+
+	// External clipboard changes restart from the latest entry.
+	drv.WriteClipboard("e")
+	require.NoError(t, c.PastePrev())
+	assert.Equal(t, "d", drv.Pasted()[4])
+	assert.Equal(t, []string{"c", "d", "e"}, c.History())
+
+	// Re-used entries (incl. pasted ones) move to the end of the history.
+	require.NoError(t, c.Set("c"))
+	assert.Equal(t, []string{"e", "d", "c"}, c.History())
+}
+
+func TestClipboardDriverErrors(t *testing.T) {
+	t.Parallel()
+
+	errWrite := errors.New("write failed")
+	drv := new(mocks.Driver)
+	drv.On("ReadClipboard").Return("foo", nil)
+	drv.On("WriteClipboard", mock.Anything).Return(errWrite)
+	c := actions.NewClipboard(drv, 8)
+
+	assert.ErrorIs(t, c.Set("bar"), errWrite)
+	assert.ErrorIs(t, c.Transform(context.Background(), "cat"), errWrite)
+	assert.Equal(t, []string{"foo"}, c.History())
+	drv.AssertNotCalled(t, "KeyTap", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,106 @@
+package actions
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock describes a source of time of timed actions.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine once duration d has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer describes a pending call scheduled via a Clock.
+type Timer interface {
+	// Stop cancels the call, and reports whether it was still pending.
+	Stop() bool
+}
+
+// SystemClock implements a Clock based on the system time.
+type SystemClock struct{}
+
+// Now gets the current system time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc calls f after duration d.
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// FakeClock implements a manually advanced Clock.
+//
+// Scheduled calls are run synchronously by Advance, in order of their due
+// time.
+type FakeClock struct {
+	now    time.Time
+	timers []*fakeTimer
+	mx     sync.Mutex
+}
+
+// NewFakeClock creates a new fake clock starting at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now gets the current time of c.
+func (c *FakeClock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.now
+}
+
+// AfterFunc schedules a call of f once c has been advanced by duration d.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	t := &fakeTimer{c: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves c forward by duration d, running all calls due until then.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mx.Lock()
+	end := c.now.Add(d)
+	for {
+		sort.SliceStable(c.timers, func(i, j int) bool {
+			return c.timers[i].at.Before(c.timers[j].at)
+		})
+		if len(c.timers) == 0 || c.timers[0].at.After(end) {
+			break
+		}
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		if t.at.After(c.now) {
+			c.now = t.at
+		}
+		c.mx.Unlock()
+		t.f()
+		c.mx.Lock()
+	}
+	c.now = end
+	c.mx.Unlock()
+}
+
+type fakeTimer struct {
+	c  *FakeClock
+	at time.Time
+	f  func()
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.c.mx.Lock()
+	defer t.c.mx.Unlock()
+	for i, ct := range t.c.timers {
+		if ct == t {
+			t.c.timers = append(t.c.timers[:i], t.c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package actions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(100, 0)
+	c := actions.NewFakeClock(start)
+	assert.Equal(t, start, c.Now())
+
+	var got []string
+	var gotAt []time.Duration
+	record := func(name string) func() {
+		return func() {
+			got = append(got, name)
+			gotAt = append(gotAt, c.Now().Sub(start))
+		}
+	}
+	c.AfterFunc(time.Second*2, record("b"))
+	c.AfterFunc(time.Second, record("a"))
+	stopped := c.AfterFunc(time.Second, record("x"))
+	c.AfterFunc(time.Second*3, func() {
+		record("c")()
+		c.AfterFunc(time.Second, record("d"))
+	})
+
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	c.Advance(time.Millisecond * 999)
+	assert.Empty(t, got)
+
+	c.Advance(time.Second * 10)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, got)
+	assert.Equal(t, []time.Duration{time.Second, time.Second * 2, time.Second * 3, time.Second * 4}, gotAt)
+	assert.Equal(t, start.Add(time.Millisecond*10999), c.Now())
+}
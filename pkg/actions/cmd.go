@@ -0,0 +1,358 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command output modes.
+const (
+	CmdOutputDiscard   = "discard"
+	CmdOutputLog       = "log"
+	CmdOutputType      = "type"
+	CmdOutputClipboard = "clipboard"
+	CmdOutputNotify    = "notify"
+)
+
+// cmdWaitDelay limits how long a command's output pipes are drained after it
+// exited or was killed.
+const cmdWaitDelay = time.Second
+
+// Command output limits.
+const (
+	// cmdOutputLimit limits the captured standard output of commands; excess
+	// output is discarded.
+	cmdOutputLimit = 1 << 20
+	// cmdStderrLimit limits the captured tail of the standard error output of
+	// commands, as reported on failures.
+	cmdStderrLimit = 4 << 10
+)
+
+// Command errors raised by package actions.
+var (
+	ErrInvalidCmdOutput = errors.New("command output mode is invalid")
+	ErrTemplatedScript  = errors.New("shell script must not contain placeholders")
+)
+
+// Cmd holds the options of a command action.
+type Cmd struct {
+	Name string
+	Args []string
+	// Shell runs Name as a script via a shell, with Args as positional
+	// parameters. The shell is either "sh"-compatible or "cmd" (Windows).
+	Shell string
+	// Env holds additional environment variables.
+	Env map[string]string
+	// Dir is the working directory; defaults to the current directory.
+	Dir string
+	// Stdin is passed to the standard input of the command.
+	Stdin string
+	// Timeout kills the command after a duration; zero disables the timeout.
+	Timeout time.Duration
+	// Wait blocks the action until the command exited.
+	Wait bool
+	// Policy is the instance policy, i.e. one of the Policy* constants.
+	Policy string
+	// Key identifies the command for its instance policy; NewCmd defaults it to
+	// a key unique to the action.
+	Key string
+	// Output is the output mode, i.e. one of the CmdOutput* constants.
+	Output string
+}
+
+// NewCmd creates an action that runs a command.
+//
+// Waiting actions return failures, and kill the command once their context is
+// done. Other actions run the command in the background, and report failures to
+// the failure handler (see SetFailureHandler). When drv is nil, the default
+// driver is used to handle output.
+func NewCmd(c Cmd, drv Driver) (Action, error) {
+	switch c.Output {
+	case "":
+		c.Output = CmdOutputDiscard
+	case CmdOutputDiscard, CmdOutputLog, CmdOutputType,
+		CmdOutputClipboard, CmdOutputNotify:
+	default:
+		return nil, ErrInvalidCmdOutput
+	}
+	if err := CheckPolicy(c.Policy); err != nil {
+		return nil, err
+	}
+	defaultKey := c.Key == ""
+	if defaultKey {
+		c.Key = newSupervisorKey()
+	}
+	// keyed gets the command to run within ctx.
+	keyed := func(ctx context.Context) Cmd {
+		c := c
+		if key, ok := supervisorKeyFrom(ctx); ok && defaultKey {
+			c.Key = key
+		}
+		return c
+	}
+	ch := &cmdOutputHandler{drv: drv}
+	run := func(ctx context.Context, c Cmd) error {
+		out, err := c.Run(ctx)
+		if errors.Is(err, ErrProcessRunning) || errors.Is(err, ErrProcessRestarted) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return ch.handle(c.Output, out)
+	}
+	if c.Wait {
+		return func(ctx context.Context, _ Trigger) error {
+			return run(ctx, keyed(ctx))
+		}, nil
+	}
+	runAsync := func(ctx context.Context, _ Trigger) error {
+		c := keyed(ctx)
+		go func() {
+			if err := run(context.Background(), c); err != nil {
+				reportFailure("os:cmd", err)
+			}
+		}()
+		return nil
+	}
+	return runAsync, nil
+}
+
+// Run runs c to completion via the default supervisor and gets its standard
+// output, unless discarded via the CmdOutputDiscard output mode.
+//
+// Unsuccessful exits are returned as errors, including the last line of the
+// standard error output. The command is killed once ctx is done.
+func (c Cmd) Run(ctx context.Context) (string, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	cmd := c.command(ctx)
+	stdout := &limitedBuffer{limit: cmdOutputLimit}
+	stderr := &tailBuffer{limit: cmdStderrLimit}
+	// Discarded output is sent to the null device.
+	if c.Output != CmdOutputDiscard {
+		cmd.Stdout = stdout
+	}
+	cmd.Stderr = stderr
+	if c.Stdin != "" {
+		cmd.Stdin = strings.NewReader(c.Stdin)
+	}
+
+	err := DefaultSupervisor().Run(c.Key, c.Policy, cmd)
+	if errors.Is(err, ErrProcessRunning) || errors.Is(err, ErrProcessRestarted) {
+		return "", err
+	}
+	if ctx.Err() != nil {
+		return stdout.String(), fmt.Errorf("%s: %w", c.Name, ctx.Err())
+	}
+	if err != nil {
+		if msg := lastLine(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("%s: %w: %s", c.Name, err, msg)
+		}
+		return stdout.String(), fmt.Errorf("%s: %w", c.Name, err)
+	}
+	return stdout.String(), nil
+}
+
+func (c Cmd) command(ctx context.Context) *exec.Cmd {
+	var cmd *exec.Cmd
+	switch c.Shell {
+	case "":
+		cmd = exec.CommandContext(ctx, c.Name, c.Args...)
+	case "cmd":
+		args := append([]string{"/C", c.Name}, c.Args...)
+		cmd = exec.CommandContext(ctx, c.Shell, args...)
+	default:
+		// Positional parameters of sh-compatible shells start after "$0".
+		args := append([]string{"-c", c.Name, "mouser"}, c.Args...)
+		cmd = exec.CommandContext(ctx, c.Shell, args...)
+	}
+	cmd.Cancel = func() error { return killChild(cmd) }
+	cmd.Dir = c.Dir
+	cmd.WaitDelay = cmdWaitDelay
+	if len(c.Env) > 0 {
+		cmd.Env = os.Environ()
+		for k, v := range c.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
+	return cmd
+}
+
+// limitedBuffer implements a writer capturing up to the first limit bytes,
+// and discarding the rest.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// tailBuffer implements a writer capturing the last limit bytes.
+type tailBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(p) > b.limit {
+		p = p[len(p)-b.limit:]
+	}
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.limit; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return n, nil
+}
+
+func (b *tailBuffer) String() string {
+	return string(b.buf)
+}
+
+func lastLine(str string) string {
+	str = strings.TrimRight(str, "\r\n")
+	return strings.TrimSpace(str[strings.LastIndex(str, "\n")+1:])
+}
+
+// cmdOutputHandler handles command output.
+type cmdOutputHandler struct {
+	drv Driver
+
+	clip     *Clipboard
+	clipOnce sync.Once
+}
+
+var cmdOutputLogger = newTopicLogger("Output")
+
+func (ch *cmdOutputHandler) handle(mode, out string) error {
+	out = strings.TrimRight(out, "\r\n")
+	switch mode {
+	case CmdOutputLog:
+		cmdOutputLogger.Printf("Action=os:cmd Out=%q", out)
+	case CmdOutputType:
+		driverOrDefault(ch.drv).TypeText(out)
+	case CmdOutputClipboard:
+		return ch.clipboard().Set(out)
+	case CmdOutputNotify:
+		return DefaultNotifier().Notify(Notification{Title: notifyAppName, Body: out})
+	}
+	return nil
+}
+
+func (ch *cmdOutputHandler) clipboard() *Clipboard {
+	ch.clipOnce.Do(func() {
+		if ch.drv == nil {
+			ch.clip = DefaultClipboard()
+		} else {
+			ch.clip = NewClipboard(ch.drv, defaultClipHistorySize)
+		}
+	})
+	return ch.clip
+}
+
+// checkCmdTemplates checks the parsed templated arguments of an os:cmd action.
+//
+// Expanded placeholders within shell scripts could inject shell code (e.g.
+// via the clipboard), so scripts must pass them as positional parameters
+// instead. The "cmd" shell re-parses its parameters, so these must not contain
+// placeholders either.
+func checkCmdTemplates(args []interface{}) error {
+	args, optsArg := splitActionOptions(args)
+	opts, _ := optsArg.(map[string]interface{})
+	var shell string
+	switch s := opts["shell"].(type) {
+	case bool:
+		if s {
+			shell, _ = defaultShell()
+		}
+	case string:
+		shell = s
+	case *Template:
+		return ErrTemplatedScript
+	}
+	if shell == "" || len(args) == 0 {
+		return nil
+	}
+	checked := args[:1]
+	if shell == "cmd" {
+		checked = args
+	}
+	for _, arg := range checked {
+		if _, ok := arg.(*Template); ok {
+			return ErrTemplatedScript
+		}
+	}
+	return nil
+}
+
+func parseCmd(name string, args []string, arg interface{}) (Cmd, bool) {
+	c := Cmd{Name: name, Args: args}
+	if arg == nil {
+		return c, true
+	}
+	opts, ok := parseActionOptions(
+		arg,
+		"shell", "env", "cwd", "stdin", "timeout", "wait", "output", "policy",
+	)
+	if !ok {
+		return c, false
+	}
+	switch shell := opts["shell"].(type) {
+	case nil:
+	case bool:
+		if shell {
+			c.Shell, _ = defaultShell()
+		}
+	case string:
+		c.Shell = shell
+	default:
+		return c, false
+	}
+	if c.Env, ok = opts.stringMap("env"); !ok {
+		return c, false
+	}
+	if c.Dir, ok = opts.string("cwd", ""); !ok {
+		return c, false
+	}
+	if c.Stdin, ok = opts.string("stdin", ""); !ok {
+		return c, false
+	}
+	if c.Timeout, ok = opts.duration("timeout", 0); !ok {
+		return c, false
+	}
+	if c.Wait, ok = opts.bool("wait", false); !ok {
+		return c, false
+	}
+	if c.Output, ok = opts.string("output", ""); !ok {
+		return c, false
+	}
+	if c.Policy, ok = opts.string("policy", ""); !ok {
+		return c, false
+	}
+	return c, true
+}
@@ -0,0 +1,274 @@
+package actions_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	realDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		cmd     actions.Cmd
+		want    string
+		wantErr string
+	}{
+		{
+			"runs command",
+			actions.Cmd{Name: "echo", Args: []string{"foo", "bar"}},
+			"foo bar\n", "",
+		},
+		{
+			"runs shell script with args",
+			actions.Cmd{Name: `echo "$1-$2"`, Args: []string{"foo", "bar"}, Shell: "sh"},
+			"foo-bar\n", "",
+		},
+		{
+			"passes stdin",
+			actions.Cmd{Name: "cat", Stdin: "foo"},
+			"foo", "",
+		},
+		{
+			"passes env",
+			actions.Cmd{Name: "echo $FOO", Shell: "sh", Env: map[string]string{"FOO": "bar"}},
+			"bar\n", "",
+		},
+		{
+			"sets cwd",
+			actions.Cmd{Name: "pwd", Dir: dir},
+			realDir + "\n", "",
+		},
+		{
+			"reports exit status & stderr",
+			actions.Cmd{Name: "echo foo; echo oops >&2; exit 3", Shell: "sh"},
+			"foo\n", "exit status 3: oops",
+		},
+		{
+			"discards output",
+			actions.Cmd{Name: "echo", Args: []string{"foo"}, Output: actions.CmdOutputDiscard},
+			"", "",
+		},
+		{
+			"reports stderr tail",
+			actions.Cmd{Name: "yes x | head -c 100000 >&2; echo oops >&2; exit 3", Shell: "sh"},
+			"", "exit status 3: oops",
+		},
+		{
+			"reports missing command",
+			actions.Cmd{Name: "mouser-missing-cmd"},
+			"", "not found",
+		},
+		{
+			"kills on timeout",
+			actions.Cmd{Name: "sleep", Args: []string{"5"}, Timeout: time.Millisecond * 50},
+			"", context.DeadlineExceeded.Error(),
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			start := time.Now()
+			got, err := tc.cmd.Run(context.Background())
+			assert.Less(t, time.Since(start), time.Second*2)
+			assert.Equal(t, tc.want, got)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCmdRunOutputLimit(t *testing.T) {
+	t.Parallel()
+	got, err := actions.Cmd{Name: "head -c 2000000 /dev/zero", Shell: "sh"}.Run(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, got, 1<<20)
+}
+
+func TestCmdRunExitError(t *testing.T) {
+	t.Parallel()
+	_, err := actions.Cmd{Name: "false"}.Run(context.Background())
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+}
+
+func TestNewCmdOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		output        string
+		wantTyped     string
+		wantClipboard string
+	}{
+		{actions.CmdOutputDiscard, "", ""},
+		{actions.CmdOutputLog, "", ""},
+		{actions.CmdOutputType, "foo bar", ""},
+		{actions.CmdOutputClipboard, "", "foo bar"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.output, func(t *testing.T) {
+			t.Parallel()
+			drv := new(actions.FakeDriver)
+			a, err := actions.NewCmd(actions.Cmd{
+				Name:   "echo",
+				Args:   []string{"foo", "bar"},
+				Wait:   true,
+				Output: tc.output,
+			}, drv)
+			require.NoError(t, err)
+			require.NoError(t, a(context.Background(), actions.Trigger{}))
+			assert.Equal(t, tc.wantTyped, drv.Typed())
+			got, _ := drv.ReadClipboard()
+			assert.Equal(t, tc.wantClipboard, got)
+		})
+	}
+
+	_, err := actions.NewCmd(actions.Cmd{Name: "echo", Output: "foo"}, nil)
+	assert.ErrorIs(t, err, actions.ErrInvalidCmdOutput)
+}
+
+func TestNewCmdAsync(t *testing.T) {
+	t.Parallel()
+
+	out := filepath.Join(t.TempDir(), "out")
+	a, err := actions.NewCmd(actions.Cmd{
+		Name:  "sleep 0.1; echo foo > " + out,
+		Shell: "sh",
+	}, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	assert.NoError(t, a(context.Background(), actions.Trigger{}))
+	assert.Less(t, time.Since(start), time.Millisecond*100)
+	assert.Eventually(t, func() bool {
+		got, _ := os.ReadFile(out)
+		return string(got) == "foo\n"
+	}, time.Second, time.Millisecond*10)
+}
+
+type testLogger struct {
+	lines []string
+	mx    sync.Mutex
+}
+
+func (l *testLogger) Printf(format string, args ...interface{}) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Lines() []string {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return append([]string{}, l.lines...)
+}
+
+func TestNewCmdOutputLogger(t *testing.T) {
+	logger := new(testLogger)
+	actions.SetLogger(logger)
+	t.Cleanup(func() { actions.SetLogger(nil) })
+
+	a, err := actions.New("os:cmd", "echo", "mouser-logged", map[string]interface{}{
+		"wait":   true,
+		"output": "log",
+	})
+	require.NoError(t, err)
+	require.NoError(t, a(context.Background(), actions.Trigger{}))
+	assert.Contains(t, logger.Lines(), `Action=os:cmd Out="mouser-logged"`)
+}
+
+func TestNewCmdFailures(t *testing.T) {
+	var mx sync.Mutex
+	var failures []string
+	actions.SetFailureHandler(func(actionName string, err error) {
+		mx.Lock()
+		defer mx.Unlock()
+		failures = append(failures, actionName+": "+err.Error())
+	})
+	t.Cleanup(func() { actions.SetFailureHandler(nil) })
+
+	// Waiting commands return failures.
+	a, err := actions.New("os:cmd", "echo oops >&2; exit 1", map[string]interface{}{
+		"shell": true,
+		"wait":  true,
+	})
+	require.NoError(t, err)
+	assert.ErrorContains(t, a(context.Background(), actions.Trigger{}), "oops")
+
+	// Background commands report failures.
+	a, err = actions.New("os:cmd", "echo oops >&2; exit 1", map[string]interface{}{
+		"shell": true,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, a(context.Background(), actions.Trigger{}))
+	require.Eventually(t, func() bool {
+		mx.Lock()
+		defer mx.Unlock()
+		return len(failures) == 1
+	}, time.Second, time.Millisecond*10)
+
+	mx.Lock()
+	defer mx.Unlock()
+	assert.True(t, strings.HasPrefix(failures[0], "os:cmd: "))
+	assert.Contains(t, failures[0], "oops")
+}
+
+func TestNewCmdCancel(t *testing.T) {
+	t.Parallel()
+
+	a, err := actions.NewCmd(actions.Cmd{
+		Name: "sleep",
+		Args: []string{"5"},
+		Wait: true,
+	}, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	start := time.Now()
+	err = a(ctx, actions.Trigger{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second*2)
+}
+
+func TestNewCmdPolicies(t *testing.T) {
+	t.Parallel()
+
+	out := filepath.Join(t.TempDir(), "out")
+	a, err := actions.NewCmd(actions.Cmd{
+		Name:   "echo x >> " + out + "; sleep 0.2",
+		Shell:  "sh",
+		Policy: actions.PolicySingleInstance,
+	}, nil)
+	require.NoError(t, err)
+
+	a(context.Background(), actions.Trigger{})
+	time.Sleep(time.Millisecond * 50)
+	a(context.Background(), actions.Trigger{})
+	time.Sleep(time.Millisecond * 300)
+	got, _ := os.ReadFile(out)
+	assert.Equal(t, "x\n", string(got))
+
+	_, err = actions.NewCmd(actions.Cmd{Name: "echo", Policy: "foo"}, nil)
+	assert.ErrorIs(t, err, actions.ErrInvalidPolicy)
+}
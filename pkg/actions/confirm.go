@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Confirmation feedback modes.
+const (
+	ConfirmFeedbackNotify = "notify"
+	ConfirmFeedbackLog    = "log"
+	ConfirmFeedbackNone   = "none"
+)
+
+// defaultConfirmMessage is the feedback message of confirmations without
+// explicit message.
+const defaultConfirmMessage = "Trigger again to confirm"
+
+// Confirmation errors raised by package actions.
+var (
+	ErrInvalidConfirmWindow   = errors.New("confirmation window must be positive")
+	ErrInvalidConfirmFeedback = errors.New("confirmation feedback mode is invalid")
+)
+
+// Confirm holds the options of a confirmation action.
+type Confirm struct {
+	// Window is the time within which a second trigger confirms the action.
+	Window time.Duration
+	// Feedback is the arming feedback mode, i.e. one of the ConfirmFeedback*
+	// constants; defaults to ConfirmFeedbackNotify.
+	Feedback string
+	// Message is the arming feedback message.
+	Message string
+}
+
+var confirmLogger = newTopicLogger("Confirm")
+
+// NewConfirm creates an action triggering a only when confirmed.
+//
+// The first trigger arms the action and gives feedback. A second trigger
+// within the confirmation window triggers a; otherwise the action disarms
+// once the window expired.
+func NewConfirm(a Action, c Confirm) (Action, error) {
+	return NewConfirmCustom(a, c, nil, SystemClock{})
+}
+
+// NewConfirmCustom creates a confirmation action based on a custom notifier
+// and a custom clock.
+//
+// A nil n uses the default notifier (see DefaultNotifier).
+func NewConfirmCustom(
+	a Action,
+	c Confirm,
+	n Notifier,
+	clock Clock,
+) (Action, error) {
+	if c.Window <= 0 {
+		return nil, ErrInvalidConfirmWindow
+	}
+	if c.Message == "" {
+		c.Message = defaultConfirmMessage
+	}
+	var feedback func()
+	switch c.Feedback {
+	case ConfirmFeedbackNotify, "":
+		feedback = func() {
+			nn := n
+			if nn == nil {
+				nn = DefaultNotifier()
+			}
+			// Feedback failures do not prevent arming, and are merely logged.
+			err := nn.Notify(Notification{Title: notifyAppName, Body: c.Message})
+			if err != nil {
+				confirmLogger.Printf("Feedback=notify Err=%s", err)
+			}
+		}
+	case ConfirmFeedbackLog:
+		feedback = func() {
+			confirmLogger.Printf("%s", c.Message)
+		}
+	case ConfirmFeedbackNone:
+		feedback = func() {}
+	default:
+		return nil, ErrInvalidConfirmFeedback
+	}
+
+	var (
+		mx      sync.Mutex
+		armedAt time.Time
+		armed   bool
+	)
+	confirm := func(ctx context.Context, ev Trigger) error {
+		mx.Lock()
+		now := clock.Now()
+		if armed && now.Sub(armedAt) < c.Window {
+			armed = false
+			mx.Unlock()
+			return runAction(ctx, a, ev)
+		}
+		armed = true
+		armedAt = now
+		mx.Unlock()
+		feedback()
+		return nil
+	}
+	return confirm, nil
+}
@@ -0,0 +1,81 @@
+package actions_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/echocrow/Mouser/pkg/actions/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfirm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		triggers []int
+		want     int
+		wantArms int
+	}{
+		{"single", []int{0}, 0, 1},
+		{"confirmed", []int{0, 500}, 1, 1},
+		{"expired", []int{0, 1000}, 0, 2},
+		{"re-armed", []int{0, 1500, 1600}, 1, 2},
+		{"confirmed twice", []int{0, 100, 200, 300}, 2, 2},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := actions.NewFakeClock(time.Unix(0, 0))
+			start := clock.Now()
+			n := new(mocks.Notifier)
+			n.On("Notify", actions.Notification{Title: "Mouser", Body: "Sure?"}).Return(nil)
+
+			ran := 0
+			c, err := actions.NewConfirmCustom(
+				newTestAction(func() { ran++ }),
+				actions.Confirm{Window: time.Second, Message: "Sure?"},
+				n,
+				clock,
+			)
+			require.NoError(t, err)
+			for _, at := range tc.triggers {
+				clock.Advance(start.Add(time.Duration(at) * time.Millisecond).Sub(clock.Now()))
+				c(context.Background(), actions.Trigger{})
+			}
+			assert.Equal(t, tc.want, ran)
+			n.AssertNumberOfCalls(t, "Notify", tc.wantArms)
+		})
+	}
+}
+
+func TestNewConfirmFeedback(t *testing.T) {
+	t.Parallel()
+	clock := actions.NewFakeClock(time.Unix(0, 0))
+	n := new(mocks.Notifier)
+	n.On("Notify", actions.Notification{Title: "Mouser", Body: "Trigger again to confirm"}).
+		Return(errors.New("nope"))
+
+	c, err := actions.NewConfirmCustom(nil, actions.Confirm{Window: time.Second}, n, clock)
+	require.NoError(t, err)
+	assert.NoError(t, c(context.Background(), actions.Trigger{}), "want feedback errors to be non-fatal")
+	assert.NoError(t, c(context.Background(), actions.Trigger{}))
+
+	for _, feedback := range []string{"log", "none"} {
+		c, err := actions.NewConfirmCustom(nil, actions.Confirm{Window: time.Second, Feedback: feedback}, n, clock)
+		require.NoError(t, err)
+		assert.NoError(t, c(context.Background(), actions.Trigger{}))
+	}
+	n.AssertNumberOfCalls(t, "Notify", 1)
+
+	_, err = actions.NewConfirm(nil, actions.Confirm{})
+	assert.ErrorIs(t, err, actions.ErrInvalidConfirmWindow)
+	_, err = actions.NewConfirm(nil, actions.Confirm{Window: time.Second, Feedback: "foo"})
+	assert.ErrorIs(t, err, actions.ErrInvalidConfirmFeedback)
+}
@@ -0,0 +1,125 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Cycle modes.
+const (
+	CycleRoundRobin = "round-robin"
+	CyclePingPong   = "ping-pong"
+	CycleRandom     = "random"
+)
+
+// Cycle errors raised by package actions.
+var (
+	ErrInvalidCycleMode = errors.New("cycle mode is invalid")
+	ErrEmptyCycle       = errors.New("cycle has no actions")
+)
+
+// NewCycle creates an action triggering the next of a list of actions each
+// time it is triggered.
+//
+// Mode is one of the Cycle* constants, and defaults to CycleRoundRobin. When
+// resetAfter is non-zero, the cycle restarts at the first action once it has
+// not been triggered for the resetAfter duration.
+func NewCycle(
+	as []Action,
+	mode string,
+	resetAfter time.Duration,
+) (Action, error) {
+	return NewCycleCustom(as, mode, resetAfter, SystemClock{}, rand.Intn)
+}
+
+// NewCycleCustom creates a cycle action based on a custom clock and a custom
+// random number generator.
+func NewCycleCustom(
+	as []Action,
+	mode string,
+	resetAfter time.Duration,
+	clock Clock,
+	randIntn func(n int) int,
+) (Action, error) {
+	if len(as) == 0 {
+		return nil, ErrEmptyCycle
+	}
+	var next func(c *cycleState) int
+	switch mode {
+	case CycleRoundRobin, "":
+		next = (*cycleState).nextRoundRobin
+	case CyclePingPong:
+		next = (*cycleState).nextPingPong
+	case CycleRandom:
+		next = func(c *cycleState) int { return c.nextRandom(randIntn) }
+	default:
+		return nil, ErrInvalidCycleMode
+	}
+
+	c := &cycleState{n: len(as)}
+	cycle := func(ctx context.Context, ev Trigger) error {
+		c.mx.Lock()
+		t := clock.Now()
+		if resetAfter > 0 && !c.last.IsZero() && t.Sub(c.last) >= resetAfter {
+			c.reset()
+		}
+		c.last = t
+		i := next(c)
+		c.mx.Unlock()
+
+		return runAction(ctx, as[i], ev)
+	}
+	return cycle, nil
+}
+
+// cycleState holds the position of a cycle action.
+type cycleState struct {
+	n       int
+	i       int
+	dir     int
+	started bool
+	last    time.Time
+	mx      sync.Mutex
+}
+
+func (c *cycleState) reset() {
+	c.i = 0
+	c.started = false
+}
+
+func (c *cycleState) nextRoundRobin() int {
+	i := c.i
+	c.i = (c.i + 1) % c.n
+	return i
+}
+
+func (c *cycleState) nextPingPong() int {
+	i := c.i
+	if c.n > 1 {
+		if i == 0 {
+			c.dir = 1
+		} else if i == c.n-1 {
+			c.dir = -1
+		}
+		c.i += c.dir
+	}
+	return i
+}
+
+// nextRandom picks a random action, avoiding immediate repeats.
+func (c *cycleState) nextRandom(randIntn func(n int) int) int {
+	if !c.started || c.n == 1 {
+		c.started = true
+		c.i = randIntn(c.n)
+		return c.i
+	}
+	i := randIntn(c.n - 1)
+	if i >= c.i {
+		i++
+	}
+	c.i = i
+	return i
+}
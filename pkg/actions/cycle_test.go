@@ -0,0 +1,119 @@
+package actions_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCycle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		n          int
+		mode       string
+		resetAfter time.Duration
+		rands      []int
+		gaps       []time.Duration
+		want       []int
+	}{
+		{"round-robin", 3, actions.CycleRoundRobin, 0, nil, nil, []int{0, 1, 2, 0, 1, 2, 0}},
+		{"default mode", 2, "", 0, nil, nil, []int{0, 1, 0, 1}},
+		{"single", 1, actions.CycleRoundRobin, 0, nil, nil, []int{0, 0, 0}},
+		{"ping-pong", 3, actions.CyclePingPong, 0, nil, nil, []int{0, 1, 2, 1, 0, 1, 2, 1}},
+		{"ping-pong pair", 2, actions.CyclePingPong, 0, nil, nil, []int{0, 1, 0, 1}},
+		{"ping-pong single", 1, actions.CyclePingPong, 0, nil, nil, []int{0, 0}},
+		{
+			"random without repeats", 3, actions.CycleRandom, 0,
+			[]int{1, 1, 0, 1, 0},
+			nil,
+			[]int{1, 2, 0, 2, 0},
+		},
+		{
+			"reset after idle", 3, actions.CycleRoundRobin, time.Second,
+			nil,
+			[]time.Duration{0, 500, 999, 1000, 200, 5000},
+			[]int{0, 1, 2, 0, 1, 0},
+		},
+		{
+			"ping-pong reset", 3, actions.CyclePingPong, time.Second,
+			nil,
+			[]time.Duration{0, 10, 10, 10, 1000, 10},
+			[]int{0, 1, 2, 1, 0, 1},
+		},
+		{
+			"random reset", 3, actions.CycleRandom, time.Second,
+			[]int{2, 1, 2},
+			[]time.Duration{0, 10, 1000},
+			[]int{2, 1, 2},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []int
+			as := make([]actions.Action, tc.n)
+			for i := range as {
+				i := i
+				as[i] = newTestAction(func() { got = append(got, i) })
+			}
+
+			clock := actions.NewFakeClock(time.Unix(0, 0))
+			rands := tc.rands
+			randIntn := func(n int) int {
+				require.NotEmpty(t, rands)
+				r := rands[0]
+				rands = rands[1:]
+				require.Less(t, r, n)
+				return r
+			}
+
+			c, err := actions.NewCycleCustom(as, tc.mode, tc.resetAfter, clock, randIntn)
+			require.NoError(t, err)
+			for i := range tc.want {
+				if i < len(tc.gaps) {
+					clock.Advance(tc.gaps[i] * time.Millisecond)
+				}
+				c(context.Background(), actions.Trigger{})
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewCycleNilActions(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	record := func(name string) actions.Action {
+		return newTestAction(func() { got = append(got, name) })
+	}
+	c, err := actions.NewCycle([]actions.Action{record("a"), nil, record("c")}, "", 0)
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		c(context.Background(), actions.Trigger{})
+	}
+	assert.Equal(t, []string{"a", "c", "a"}, got)
+}
+
+func TestNewCycleErrors(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAction(func() {})
+	_, err := actions.NewCycle(nil, "", 0)
+	assert.ErrorIs(t, err, actions.ErrEmptyCycle)
+	_, err = actions.NewCycle([]actions.Action{a}, "foo", 0)
+	assert.ErrorIs(t, err, actions.ErrInvalidCycleMode)
+	for _, mode := range []string{"round-robin", "ping-pong", "random"} {
+		_, err = actions.NewCycle([]actions.Action{a}, mode, 0)
+		assert.NoError(t, err, strconv.Quote(mode))
+	}
+}
@@ -0,0 +1,397 @@
+package actions
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/go-vgo/robotgo"
+)
+
+// Driver describes a system input/output driver used by actions.
+//go:generate mockery --name "Driver"
+type Driver interface {
+	PointerPos() (x, y int)
+	MoveTo(x, y int)
+	ScreenSize() (w, h int)
+	Click(button string, count int)
+	MouseToggle(button string, down bool)
+	Scroll(x, y int)
+	ReadClipboard() (string, error)
+	WriteClipboard(text string) error
+	KeyTap(key string, modifiers ...string)
+	KeyToggle(key string, down bool)
+	TypeText(text string)
+	CloseWindow()
+}
+
+// DefaultDriver gets the default system input/output driver.
+//
+// The default driver passes keys on to the driver set via SetDriver, sending
+// physical keys (i.e. "phys:<us-key>" or "sc:<scancode>") as scancodes where
+// supported (see ScancodeDriver). Characters are passed on as-is, unless a
+// layout was set via SetKeyLayout.
+func DefaultDriver() Driver {
+	return &LayoutDriver{Driver: physKeyDriver{sharedBaseDriver{}}, dynamic: true}
+}
+
+var (
+	sharedDriver   Driver = RobotGoDriver{}
+	sharedDriverMx sync.RWMutex
+)
+
+// SetDriver sets the underlying driver of the default driver.
+//
+// A nil drv restores the robotgo driver.
+func SetDriver(drv Driver) {
+	if drv == nil {
+		drv = RobotGoDriver{}
+	}
+	sharedDriverMx.Lock()
+	defer sharedDriverMx.Unlock()
+	sharedDriver = drv
+}
+
+func getDriver() Driver {
+	sharedDriverMx.RLock()
+	defer sharedDriverMx.RUnlock()
+	return sharedDriver
+}
+
+// sharedBaseDriver implements a system input/output driver passing calls on to
+// the driver set via SetDriver.
+type sharedBaseDriver struct{}
+
+func (sharedBaseDriver) PointerPos() (x, y int) {
+	return getDriver().PointerPos()
+}
+
+func (sharedBaseDriver) MoveTo(x, y int) {
+	getDriver().MoveTo(x, y)
+}
+
+func (sharedBaseDriver) ScreenSize() (w, h int) {
+	return getDriver().ScreenSize()
+}
+
+func (sharedBaseDriver) Click(button string, count int) {
+	getDriver().Click(button, count)
+}
+
+func (sharedBaseDriver) MouseToggle(button string, down bool) {
+	getDriver().MouseToggle(button, down)
+}
+
+func (sharedBaseDriver) Scroll(x, y int) {
+	getDriver().Scroll(x, y)
+}
+
+func (sharedBaseDriver) ReadClipboard() (string, error) {
+	return getDriver().ReadClipboard()
+}
+
+func (sharedBaseDriver) WriteClipboard(text string) error {
+	return getDriver().WriteClipboard(text)
+}
+
+func (sharedBaseDriver) KeyTap(key string, modifiers ...string) {
+	getDriver().KeyTap(key, modifiers...)
+}
+
+func (sharedBaseDriver) KeyToggle(key string, down bool) {
+	getDriver().KeyToggle(key, down)
+}
+
+func (sharedBaseDriver) TypeText(text string) {
+	getDriver().TypeText(text)
+}
+
+func (sharedBaseDriver) CloseWindow() {
+	getDriver().CloseWindow()
+}
+
+// shortcutModifier is the modifier key of common shortcuts (copy, paste etc.)
+// on the current platform.
+var shortcutModifier = func() string {
+	if runtime.GOOS == "darwin" {
+		return "cmd"
+	}
+	return "ctrl"
+}()
+
+// RobotGoDriver implements a system input/output driver via robotgo.
+type RobotGoDriver struct{}
+
+// PointerPos gets the current pointer position.
+func (RobotGoDriver) PointerPos() (x, y int) {
+	return robotgo.Location()
+}
+
+// MoveTo moves the pointer to an absolute position.
+func (RobotGoDriver) MoveTo(x, y int) {
+	robotgo.Move(x, y)
+}
+
+// ScreenSize gets the size of the main screen.
+func (RobotGoDriver) ScreenSize() (w, h int) {
+	return robotgo.GetScreenSize()
+}
+
+// Click clicks a mouse button count times.
+func (RobotGoDriver) Click(button string, count int) {
+	// Consecutive clicks are sent as double clicks where possible, as some
+	// platforms don't detect these from single clicks.
+	for n := count; n > 0; n -= 2 {
+		robotgo.Click(button, n >= 2)
+	}
+}
+
+// MouseToggle presses or releases a mouse button.
+func (RobotGoDriver) MouseToggle(button string, down bool) {
+	if down {
+		robotgo.Toggle(button, "down")
+	} else {
+		robotgo.Toggle(button, "up")
+	}
+}
+
+// Scroll scrolls by x pixels to the right and y pixels down.
+func (RobotGoDriver) Scroll(x, y int) {
+	robotgo.Scroll(-x, -y)
+}
+
+// ReadClipboard reads the current text clipboard contents.
+func (RobotGoDriver) ReadClipboard() (string, error) {
+	return robotgo.ReadAll()
+}
+
+// WriteClipboard writes text to the text clipboard.
+func (RobotGoDriver) WriteClipboard(text string) error {
+	return robotgo.WriteAll(text)
+}
+
+// KeyTap triggers a short key press & release while holding modifiers.
+func (RobotGoDriver) KeyTap(key string, modifiers ...string) {
+	robotgo.KeyTap(key, destringify(modifiers)...)
+}
+
+// KeyToggle presses or releases a key.
+func (RobotGoDriver) KeyToggle(key string, down bool) {
+	if down {
+		robotgo.KeyToggle(key, "down")
+	} else {
+		robotgo.KeyToggle(key, "up")
+	}
+}
+
+// TypeText types out text.
+func (RobotGoDriver) TypeText(text string) {
+	robotgo.TypeStr(text)
+}
+
+// CloseWindow closes the current window.
+func (RobotGoDriver) CloseWindow() {
+	robotgo.CloseWindow()
+}
+
+// FakeDriver implements an in-memory system input/output driver.
+//
+// Copy & paste shortcuts are emulated: copying writes the current selection
+// to the clipboard, and pasting records the current clipboard contents.
+type FakeDriver struct {
+	x, y      int
+	w, h      int
+	mouse     []string
+	clipboard string
+	selection string
+	pasted    []string
+	taps      [][]string
+	keys      []string
+	typed     strings.Builder
+	closed    int
+	mx        sync.RWMutex
+}
+
+// SetPointerPos sets the current pointer position of d.
+func (d *FakeDriver) SetPointerPos(x, y int) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.x, d.y = x, y
+}
+
+// PointerPos gets the current pointer position.
+func (d *FakeDriver) PointerPos() (x, y int) {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	return d.x, d.y
+}
+
+// MoveTo moves the pointer to an absolute position.
+func (d *FakeDriver) MoveTo(x, y int) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.x, d.y = x, y
+	d.mouse = append(d.mouse, fmt.Sprintf("move %d %d", x, y))
+}
+
+// SetScreenSize sets the size of the main screen of d.
+func (d *FakeDriver) SetScreenSize(w, h int) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.w, d.h = w, h
+}
+
+// ScreenSize gets the size of the main screen.
+func (d *FakeDriver) ScreenSize() (w, h int) {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	return d.w, d.h
+}
+
+// Click records count clicks of a mouse button.
+func (d *FakeDriver) Click(button string, count int) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	for i := 0; i < count; i++ {
+		d.mouse = append(d.mouse, "click "+button)
+	}
+}
+
+// MouseToggle records a press or release of a mouse button.
+func (d *FakeDriver) MouseToggle(button string, down bool) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	if down {
+		d.mouse = append(d.mouse, "down "+button)
+	} else {
+		d.mouse = append(d.mouse, "up "+button)
+	}
+}
+
+// Scroll records a scroll by x pixels to the right and y pixels down.
+func (d *FakeDriver) Scroll(x, y int) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.mouse = append(d.mouse, fmt.Sprintf("scroll %d %d", x, y))
+}
+
+// MouseEvents gets all mouse events triggered via d, e.g. "move 1 2",
+// "click left", "down right", "up right" or "scroll 0 5".
+func (d *FakeDriver) MouseEvents() []string {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	return append([]string{}, d.mouse...)
+}
+
+// ReadClipboard reads the current text clipboard contents.
+func (d *FakeDriver) ReadClipboard() (string, error) {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	return d.clipboard, nil
+}
+
+// WriteClipboard writes text to the text clipboard.
+func (d *FakeDriver) WriteClipboard(text string) error {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.clipboard = text
+	return nil
+}
+
+// SetSelection sets the currently selected text of d.
+func (d *FakeDriver) SetSelection(text string) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.selection = text
+}
+
+// KeyTap records a key tap and emulates copy & paste shortcuts.
+func (d *FakeDriver) KeyTap(key string, modifiers ...string) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.taps = append(d.taps, append(append([]string{}, modifiers...), key))
+	if len(modifiers) != 1 || modifiers[0] != shortcutModifier {
+		return
+	}
+	switch key {
+	case "c":
+		d.clipboard = d.selection
+	case "v":
+		d.pasted = append(d.pasted, d.clipboard)
+	}
+}
+
+// KeyToggle records a key press or release.
+func (d *FakeDriver) KeyToggle(key string, down bool) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	if down {
+		d.keys = append(d.keys, "down "+key)
+	} else {
+		d.keys = append(d.keys, "up "+key)
+	}
+}
+
+// ScancodeToggle records a press or release of a physical key by scancode,
+// e.g. "down sc:0x2c".
+func (d *FakeDriver) ScancodeToggle(code uint16, down bool) error {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	if down {
+		d.keys = append(d.keys, fmt.Sprintf("down sc:%#x", code))
+	} else {
+		d.keys = append(d.keys, fmt.Sprintf("up sc:%#x", code))
+	}
+	return nil
+}
+
+// KeyEvents gets all key presses & releases triggered via d, e.g. "down a",
+// "up cmd" or "down sc:0x2c".
+func (d *FakeDriver) KeyEvents() []string {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	return append([]string{}, d.keys...)
+}
+
+// TypeText records typed text.
+func (d *FakeDriver) TypeText(text string) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.typed.WriteString(text)
+}
+
+// Typed gets all text typed via d.
+func (d *FakeDriver) Typed() string {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	return d.typed.String()
+}
+
+// CloseWindow records closing the current window.
+func (d *FakeDriver) CloseWindow() {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.closed++
+}
+
+// ClosedWindows gets the number of windows closed via d.
+func (d *FakeDriver) ClosedWindows() int {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	return d.closed
+}
+
+// Pasted gets all texts pasted via d.
+func (d *FakeDriver) Pasted() []string {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	return append([]string{}, d.pasted...)
+}
+
+// Taps gets all key taps triggered via d, each listing modifiers and key.
+func (d *FakeDriver) Taps() [][]string {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	return append([][]string{}, d.taps...)
+}
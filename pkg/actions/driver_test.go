@@ -0,0 +1,62 @@
+package actions_test
+
+import (
+	"testing"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDriver(t *testing.T) {
+	fake := new(actions.FakeDriver)
+	actions.SetDriver(fake)
+	t.Cleanup(func() { actions.SetDriver(nil) })
+	kl, err := actions.NewKeyLayout("qwertz")
+	require.NoError(t, err)
+	actions.SetKeyLayout(kl)
+	t.Cleanup(func() { actions.SetKeyLayout(nil) })
+
+	drv := actions.DefaultDriver()
+	drv.KeyTap("y", "ctrl")
+	drv.MoveTo(3, 4)
+	drv.Scroll(0, 5)
+	drv.CloseWindow()
+	assert.Equal(t, [][]string{{"ctrl", "z"}}, fake.Taps())
+	assert.Equal(t, []string{"move 3 4", "scroll 0 5"}, fake.MouseEvents())
+	assert.Equal(t, 1, fake.ClosedWindows())
+}
+
+func TestDefaultDriverKeys(t *testing.T) {
+	fake := new(actions.FakeDriver)
+	actions.SetDriver(fake)
+	t.Cleanup(func() { actions.SetDriver(nil) })
+
+	drv := actions.DefaultDriver()
+	drv.KeyTap("z", "ctrl")
+	drv.KeyTap("é")
+	drv.KeyTap("phys:z", "ctrl")
+	drv.KeyToggle("sc:0x10", true)
+	drv.KeyToggle("sc:0x10", false)
+	assert.Equal(t, [][]string{{"ctrl", "z"}, {"é"}}, fake.Taps(), "want characters as-is")
+	assert.Equal(t, []string{
+		"down ctrl",
+		"down sc:0x2c",
+		"up sc:0x2c",
+		"up ctrl",
+		"down sc:0x10",
+		"up sc:0x10",
+	}, fake.KeyEvents())
+}
+
+func TestDefaultDriverKeysWithoutScancodes(t *testing.T) {
+	fake := new(actions.FakeDriver)
+	actions.SetDriver(struct{ actions.Driver }{fake})
+	t.Cleanup(func() { actions.SetDriver(nil) })
+
+	drv := actions.DefaultDriver()
+	drv.KeyTap("phys:z", "ctrl")
+	drv.KeyToggle("sc:0x10", true)
+	assert.Equal(t, [][]string{{"ctrl", "z"}}, fake.Taps())
+	assert.Equal(t, []string{"down q"}, fake.KeyEvents())
+}
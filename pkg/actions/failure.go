@@ -0,0 +1,33 @@
+package actions
+
+import "sync"
+
+// FailureHandler describes a handler of failed actions.
+type FailureHandler func(actionName string, err error)
+
+var (
+	failureHandler   FailureHandler
+	failureHandlerMx sync.RWMutex
+)
+
+// SetFailureHandler sets the handler of failed actions.
+//
+// Actions return their failures. Failures of work that continues in the
+// background (e.g. non-waiting commands, deferred rate-limited triggers or
+// repeating toggles) are reported to h instead. A nil handler discards
+// failures.
+func SetFailureHandler(h FailureHandler) {
+	failureHandlerMx.Lock()
+	defer failureHandlerMx.Unlock()
+	failureHandler = h
+}
+
+// reportFailure reports a failed action to the failure handler.
+func reportFailure(actionName string, err error) {
+	failureHandlerMx.RLock()
+	h := failureHandler
+	failureHandlerMx.RUnlock()
+	if h != nil && err != nil {
+		h(actionName, err)
+	}
+}
@@ -0,0 +1,182 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-vgo/robotgo"
+)
+
+// ForegroundApp describes the current foreground app and window.
+type ForegroundApp struct {
+	Path  string
+	Title string
+}
+
+// ForegroundProvider describes a foreground app detector.
+//go:generate mockery --name "ForegroundProvider"
+type ForegroundProvider interface {
+	ForegroundApp() ForegroundApp
+}
+
+// Foreground provider names.
+const (
+	ForegroundAuto    = "auto"
+	ForegroundRobotGo = "robotgo"
+	ForegroundX11     = "x11"
+	ForegroundCmd     = "cmd"
+)
+
+// cmdForegroundTimeout is the maximum duration of a foreground app command.
+const cmdForegroundTimeout = 2 * time.Second
+
+// Foreground provider errors raised by package actions.
+var (
+	ErrInvalidForeground     = errors.New("foreground provider is invalid")
+	ErrUnsupportedForeground = errors.New("foreground provider is not supported on this platform")
+)
+
+// NewForeground creates a foreground app provider by name.
+//
+// The cmd arguments are required by and only used for command-based providers.
+func NewForeground(provider string, cmd ...string) (ForegroundProvider, error) {
+	switch provider {
+	case ForegroundAuto, "":
+		return DefaultForeground(), nil
+	case ForegroundRobotGo:
+		return RobotGoForeground{}, nil
+	case ForegroundX11:
+		return newX11Foreground()
+	case ForegroundCmd:
+		if len(cmd) == 0 {
+			return nil, ErrInvalidForeground
+		}
+		return NewCmdForeground(cmd[0], cmd[1:]...), nil
+	}
+	return nil, ErrInvalidForeground
+}
+
+// DefaultForeground creates the default foreground app provider of the
+// current platform.
+func DefaultForeground() ForegroundProvider {
+	return defaultForeground()
+}
+
+// CloseForeground releases the resources held by foreground app provider fp,
+// if any (e.g. the connection of an X11 provider).
+func CloseForeground(fp ForegroundProvider) {
+	if c, ok := fp.(interface{ Close() }); ok {
+		c.Close()
+	}
+}
+
+// foregroundApp gets the current foreground app of fp, aborting the detection
+// once ctx is done if fp supports it.
+func foregroundApp(ctx context.Context, fp ForegroundProvider) ForegroundApp {
+	if c, ok := fp.(interface {
+		ForegroundAppContext(ctx context.Context) ForegroundApp
+	}); ok {
+		return c.ForegroundAppContext(ctx)
+	}
+	return fp.ForegroundApp()
+}
+
+// ForegroundFunc implements a foreground app provider via a callback.
+type ForegroundFunc func() ForegroundApp
+
+// ForegroundApp gets the current foreground app.
+func (f ForegroundFunc) ForegroundApp() ForegroundApp {
+	return f()
+}
+
+// FakeForeground implements a fake foreground app provider with a settable
+// foreground app.
+type FakeForeground struct {
+	app ForegroundApp
+	mx  sync.RWMutex
+}
+
+// Set sets the current foreground app of f.
+func (f *FakeForeground) Set(app ForegroundApp) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.app = app
+}
+
+// ForegroundApp gets the current foreground app.
+func (f *FakeForeground) ForegroundApp() ForegroundApp {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+	return f.app
+}
+
+// RobotGoForeground implements a foreground app provider via robotgo.
+type RobotGoForeground struct{}
+
+// ForegroundApp gets the current foreground app.
+func (RobotGoForeground) ForegroundApp() ForegroundApp {
+	pid := int32(robotgo.GetPid())
+	if pid == 0 {
+		return ForegroundApp{}
+	}
+	return ForegroundApp{
+		Path:  getPidPath(pid),
+		Title: robotgo.GetTitle(),
+	}
+}
+
+// CmdForeground implements a foreground app provider via an external command.
+//
+// The command must print the foreground process ID on the first line of its
+// output, and may print the foreground window title on the second line.
+type CmdForeground struct {
+	name string
+	args []string
+}
+
+// NewCmdForeground creates a new command-based foreground app provider.
+func NewCmdForeground(name string, args ...string) *CmdForeground {
+	return &CmdForeground{name, args}
+}
+
+// ForegroundApp gets the current foreground app.
+func (f *CmdForeground) ForegroundApp() ForegroundApp {
+	return f.ForegroundAppContext(context.Background())
+}
+
+// ForegroundAppContext gets the current foreground app.
+//
+// The command is killed once ctx is done, or after a timeout, in which case no
+// foreground app is detected.
+func (f *CmdForeground) ForegroundAppContext(ctx context.Context) ForegroundApp {
+	ctx, cancel := context.WithTimeout(ctx, cmdForegroundTimeout)
+	defer cancel()
+	out, err := newKillableCmd(ctx, f.name, f.args...).Output()
+	if err != nil {
+		return ForegroundApp{}
+	}
+	return parseCmdForegroundOutput(out)
+}
+
+func parseCmdForegroundOutput(out []byte) ForegroundApp {
+	var app ForegroundApp
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	if !sc.Scan() {
+		return app
+	}
+	pid, err := strconv.ParseInt(strings.TrimSpace(sc.Text()), 10, 32)
+	if err != nil || pid <= 0 {
+		return app
+	}
+	app.Path = getPidPath(int32(pid))
+	if sc.Scan() {
+		app.Title = sc.Text()
+	}
+	return app
+}
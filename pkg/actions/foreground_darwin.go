@@ -0,0 +1,9 @@
+package actions
+
+func defaultForeground() ForegroundProvider {
+	return RobotGoForeground{}
+}
+
+func newX11Foreground() (ForegroundProvider, error) {
+	return nil, ErrUnsupportedForeground
+}
@@ -0,0 +1,131 @@
+package actions
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// X11 foreground errors raised by package actions.
+var (
+	ErrX11PropertyMissing = errors.New("X11 window property is missing")
+)
+
+func defaultForeground() ForegroundProvider {
+	if f, err := NewX11Foreground(); err == nil {
+		return f
+	}
+	return RobotGoForeground{}
+}
+
+func newX11Foreground() (ForegroundProvider, error) {
+	return NewX11Foreground()
+}
+
+// X11Foreground implements a foreground app provider via the X11 EWMH
+// properties _NET_ACTIVE_WINDOW and _NET_WM_PID.
+type X11Foreground struct {
+	conn *xgb.Conn
+	root xproto.Window
+
+	activeWindow xproto.Atom
+	wmPid        xproto.Atom
+	wmName       xproto.Atom
+	utf8String   xproto.Atom
+
+	mx sync.Mutex
+}
+
+// NewX11Foreground connects to the X11 display and creates a new X11-based
+// foreground app provider.
+func NewX11Foreground() (*X11Foreground, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	f := &X11Foreground{
+		conn: conn,
+		root: xproto.Setup(conn).DefaultScreen(conn).Root,
+	}
+	for atom, name := range map[*xproto.Atom]string{
+		&f.activeWindow: "_NET_ACTIVE_WINDOW",
+		&f.wmPid:        "_NET_WM_PID",
+		&f.wmName:       "_NET_WM_NAME",
+		&f.utf8String:   "UTF8_STRING",
+	} {
+		reply, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		*atom = reply.Atom
+	}
+	return f, nil
+}
+
+// ForegroundApp gets the current foreground app.
+func (f *X11Foreground) ForegroundApp() ForegroundApp {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	win, err := f.getProp32(f.root, f.activeWindow, xproto.AtomWindow)
+	if err != nil || win == 0 {
+		return ForegroundApp{}
+	}
+
+	var app ForegroundApp
+	if pid, err := f.getProp32(xproto.Window(win), f.wmPid, xproto.AtomCardinal); err == nil {
+		app.Path = getPidPath(int32(pid))
+	}
+	app.Title = f.getTitle(xproto.Window(win))
+	return app
+}
+
+// Close closes the X11 connection of f.
+func (f *X11Foreground) Close() {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.conn.Close()
+}
+
+func (f *X11Foreground) getProp(
+	win xproto.Window,
+	prop, propType xproto.Atom,
+) (*xproto.GetPropertyReply, error) {
+	reply, err := xproto.GetProperty(
+		f.conn, false, win, prop, propType, 0, (1<<32)-1,
+	).Reply()
+	if err != nil {
+		return nil, err
+	}
+	if reply.ValueLen == 0 {
+		return nil, ErrX11PropertyMissing
+	}
+	return reply, nil
+}
+
+func (f *X11Foreground) getProp32(
+	win xproto.Window,
+	prop, propType xproto.Atom,
+) (uint32, error) {
+	reply, err := f.getProp(win, prop, propType)
+	if err != nil {
+		return 0, err
+	}
+	if reply.Format != 32 || len(reply.Value) < 4 {
+		return 0, ErrX11PropertyMissing
+	}
+	return xgb.Get32(reply.Value), nil
+}
+
+func (f *X11Foreground) getTitle(win xproto.Window) string {
+	if reply, err := f.getProp(win, f.wmName, f.utf8String); err == nil {
+		return string(reply.Value)
+	}
+	if reply, err := f.getProp(win, xproto.AtomWmName, xproto.AtomString); err == nil {
+		return string(reply.Value)
+	}
+	return ""
+}
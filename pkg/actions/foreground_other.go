@@ -0,0 +1,11 @@
+//go:build !darwin && !linux
+
+package actions
+
+func defaultForeground() ForegroundProvider {
+	return RobotGoForeground{}
+}
+
+func newX11Foreground() (ForegroundProvider, error) {
+	return nil, ErrUnsupportedForeground
+}
@@ -0,0 +1,126 @@
+package actions_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/echocrow/Mouser/pkg/actions/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewForeground(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		provider string
+		cmd      []string
+		wantOk   bool
+	}{
+		{"", nil, true},
+		{actions.ForegroundAuto, nil, true},
+		{actions.ForegroundRobotGo, nil, true},
+		{actions.ForegroundCmd, []string{"foo", "bar"}, true},
+		{actions.ForegroundCmd, nil, false},
+		{"invalid", nil, false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.provider, func(t *testing.T) {
+			t.Parallel()
+			fp, err := actions.NewForeground(tc.provider, tc.cmd...)
+			if tc.wantOk {
+				assert.NotNil(t, fp)
+				assert.NoError(t, err)
+			} else {
+				assert.Nil(t, fp)
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCmdForeground(t *testing.T) {
+	t.Parallel()
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		script string
+		want   actions.ForegroundApp
+	}{
+		{"gets pid & title", `echo $PPID; echo "Some Title"`, actions.ForegroundApp{Path: exe, Title: "Some Title"}},
+		{"gets pid only", `echo " $PPID "`, actions.ForegroundApp{Path: exe}},
+		{"ignores empty output", `true`, actions.ForegroundApp{}},
+		{"ignores invalid pid", `echo foo; echo "Some Title"`, actions.ForegroundApp{}},
+		{"ignores failed command", `echo $PPID; exit 1`, actions.ForegroundApp{}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fp := actions.NewCmdForeground("sh", "-c", tc.script)
+			assert.Equal(t, tc.want, fp.ForegroundApp())
+		})
+	}
+}
+
+func TestCmdForegroundCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	fp := actions.NewCmdForeground("sh", "-c", `echo $PPID; sleep 5 & wait`)
+	start := time.Now()
+	assert.Equal(t, actions.ForegroundApp{}, fp.ForegroundAppContext(ctx))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestForegroundFunc(t *testing.T) {
+	t.Parallel()
+	want := actions.ForegroundApp{Path: "/foo", Title: "Foo"}
+	fp := actions.ForegroundFunc(func() actions.ForegroundApp { return want })
+	assert.Equal(t, want, fp.ForegroundApp())
+}
+
+type testClosingForeground struct {
+	actions.FakeForeground
+	closed int
+}
+
+func (f *testClosingForeground) Close() { f.closed++ }
+
+func TestCloseForeground(t *testing.T) {
+	t.Parallel()
+
+	f := new(testClosingForeground)
+	actions.CloseForeground(f)
+	assert.Equal(t, 1, f.closed)
+	actions.CloseForeground(new(actions.FakeForeground))
+}
+
+func TestAppBranchForegroundProvider(t *testing.T) {
+	t.Parallel()
+
+	calls := make(chan string, 2)
+
+	fp := new(mocks.ForegroundProvider)
+	fp.On("ForegroundApp").Return(actions.ForegroundApp{Path: "/foo/bar"}).Once()
+
+	branchAction, err := actions.NewAppBranch(
+		map[string]act{"/foo": newTestAction(func() { calls <- "foo" })},
+		nil,
+		nil,
+		fp,
+	)
+	require.NoError(t, err)
+
+	assertActionCalls(t, branchAction, true, "foo", calls)
+	fp.AssertExpectations(t)
+}
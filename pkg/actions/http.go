@@ -0,0 +1,117 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultHTTPTimeout = time.Second * 10
+
+// HTTP request errors raised by package actions.
+var (
+	ErrInvalidHTTPMethod = errors.New("HTTP method is invalid")
+	ErrHTTPStatus        = errors.New("HTTP response status is not successful")
+)
+
+// HTTPRequest holds the options of an HTTP request action.
+type HTTPRequest struct {
+	// Method is the request method; defaults to GET, or to POST with a body.
+	Method string
+	URL    string
+	// Headers holds additional request headers.
+	Headers map[string]string
+	Body    string
+	// Timeout limits the total request duration; defaults to 10s.
+	Timeout time.Duration
+	// FailOnStatus treats non-2xx response statuses as failures.
+	FailOnStatus bool
+}
+
+// NewHTTPRequest creates an action that sends an HTTP request.
+//
+// The action awaits the response, and is cancelled once its context is done.
+func NewHTTPRequest(req HTTPRequest) (Action, error) {
+	if req.Method == "" {
+		req.Method = http.MethodGet
+		if req.Body != "" {
+			req.Method = http.MethodPost
+		}
+	}
+	if strings.ContainsAny(req.Method, " \t\r\n") {
+		return nil, ErrInvalidHTTPMethod
+	}
+	send := func(ctx context.Context, _ Trigger) error {
+		return req.Do(ctx)
+	}
+	return send, nil
+}
+
+// Do sends req and awaits its response, unless ctx is done first.
+func (req HTTPRequest) Do(ctx context.Context) error {
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+	r, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range req.Headers {
+		r.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if req.FailOnStatus && (res.StatusCode < 200 || res.StatusCode > 299) {
+		return fmt.Errorf("%w: %s", ErrHTTPStatus, res.Status)
+	}
+	return nil
+}
+
+func parseHTTPRequest(url string, arg interface{}) (HTTPRequest, bool) {
+	req := HTTPRequest{URL: url}
+	if arg == nil {
+		return req, true
+	}
+	opts, ok := parseActionOptions(
+		arg,
+		"method", "headers", "body", "timeout", "fail-on-status",
+	)
+	if !ok {
+		return req, false
+	}
+	if req.Method, ok = opts.string("method", ""); !ok {
+		return req, false
+	}
+	req.Method = strings.ToUpper(req.Method)
+	if req.Headers, ok = opts.stringMap("headers"); !ok {
+		return req, false
+	}
+	if req.Body, ok = opts.string("body", ""); !ok {
+		return req, false
+	}
+	if req.Timeout, ok = opts.duration("timeout", 0); !ok {
+		return req, false
+	}
+	if req.FailOnStatus, ok = opts.bool("fail-on-status", false); !ok {
+		return req, false
+	}
+	return req, true
+}
@@ -0,0 +1,123 @@
+package actions_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testHTTPRequest struct {
+	method string
+	path   string
+	header string
+	body   string
+}
+
+func newTestHTTPServer(t *testing.T, status int, delay time.Duration) (*httptest.Server, <-chan testHTTPRequest) {
+	reqs := make(chan testHTTPRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		reqs <- testHTTPRequest{r.Method, r.URL.Path, r.Header.Get("X-Foo"), string(body)}
+		time.Sleep(delay)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, reqs
+}
+
+func TestHTTPRequestDo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     actions.HTTPRequest
+		status  int
+		delay   time.Duration
+		wantReq testHTTPRequest
+		wantOk  bool
+	}{
+		{
+			"sends GET request",
+			actions.HTTPRequest{Method: "GET"},
+			200, 0,
+			testHTTPRequest{"GET", "/foo", "", ""},
+			true,
+		},
+		{
+			"sends headers & body",
+			actions.HTTPRequest{
+				Method:  "PUT",
+				Headers: map[string]string{"X-Foo": "bar"},
+				Body:    `{"foo":1}`,
+			},
+			200, 0,
+			testHTTPRequest{"PUT", "/foo", "bar", `{"foo":1}`},
+			true,
+		},
+		{
+			"ignores error status by default",
+			actions.HTTPRequest{Method: "GET"},
+			500, 0,
+			testHTTPRequest{"GET", "/foo", "", ""},
+			true,
+		},
+		{
+			"fails on error status",
+			actions.HTTPRequest{Method: "GET", FailOnStatus: true},
+			404, 0,
+			testHTTPRequest{"GET", "/foo", "", ""},
+			false,
+		},
+		{
+			"fails on timeout",
+			actions.HTTPRequest{Method: "GET", Timeout: time.Millisecond * 10},
+			200, time.Millisecond * 200,
+			testHTTPRequest{"GET", "/foo", "", ""},
+			false,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			srv, reqs := newTestHTTPServer(t, tc.status, tc.delay)
+			tc.req.URL = srv.URL + "/foo"
+			err := tc.req.Do(context.Background())
+			if tc.wantOk {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+			assert.Equal(t, tc.wantReq, <-reqs)
+		})
+	}
+}
+
+func TestHTTPRequestDoInvalidURL(t *testing.T) {
+	t.Parallel()
+	assert.Error(t, actions.HTTPRequest{URL: "::foo"}.Do(context.Background()))
+	assert.Error(t, actions.HTTPRequest{URL: ""}.Do(context.Background()))
+}
+
+func TestNewHTTPRequest(t *testing.T) {
+	t.Parallel()
+	srv, reqs := newTestHTTPServer(t, 503, 0)
+
+	a, err := actions.New("http:request", srv.URL+"/bar", map[string]interface{}{
+		"body":           "foo",
+		"fail-on-status": true,
+	})
+	require.NoError(t, err)
+	assert.Error(t, a(context.Background(), actions.Trigger{}))
+	assert.Equal(t, testHTTPRequest{"POST", "/bar", "", "foo"}, <-reqs)
+
+	_, err = actions.NewHTTPRequest(actions.HTTPRequest{Method: "GET POST"})
+	assert.ErrorIs(t, err, actions.ErrInvalidHTTPMethod)
+}
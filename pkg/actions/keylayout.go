@@ -0,0 +1,338 @@
+package actions
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Key name prefixes.
+const (
+	// keyPrefixPhys marks a key name addressing a physical key by its US layout
+	// name, bypassing layout resolution.
+	keyPrefixPhys = "phys:"
+	// keyPrefixScancode marks a key name addressing a physical key by its (set 1)
+	// scancode.
+	keyPrefixScancode = "sc:"
+)
+
+// Keyboard layout names.
+const (
+	KeyLayoutAuto = "auto"
+	KeyLayoutUS   = "us"
+	KeyLayoutFR   = "fr"
+	KeyLayoutDE   = "de"
+)
+
+// Keyboard layout errors raised by package actions.
+var (
+	ErrInvalidKeyLayout = errors.New("keyboard layout is invalid")
+)
+
+// layoutKey holds the physical key (by US layout name) producing a character.
+type layoutKey struct {
+	key   string
+	shift bool
+}
+
+// KeyLayout resolves characters to physical keys of a keyboard layout.
+//
+// Physical keys are named after the characters they produce on a US layout.
+type KeyLayout struct {
+	name  string
+	chars map[string]layoutKey
+}
+
+var keyLayoutAliases = map[string]string{
+	"qwerty": KeyLayoutUS,
+	"azerty": KeyLayoutFR,
+	"qwertz": KeyLayoutDE,
+}
+
+var keyLayouts = map[string]map[string]layoutKey{
+	KeyLayoutUS: nil,
+	KeyLayoutFR: {
+		"a": {"q", false}, "q": {"a", false},
+		"z": {"w", false}, "w": {"z", false},
+		"m": {";", false},
+		"&": {"1", false}, "1": {"1", true},
+		"é": {"2", false}, "2": {"2", true},
+		`"`: {"3", false}, "3": {"3", true},
+		"'": {"4", false}, "4": {"4", true},
+		"(": {"5", false}, "5": {"5", true},
+		"-": {"6", false}, "6": {"6", true},
+		"è": {"7", false}, "7": {"7", true},
+		"_": {"8", false}, "8": {"8", true},
+		"ç": {"9", false}, "9": {"9", true},
+		"à": {"0", false}, "0": {"0", true},
+		")": {"-", false}, "°": {"-", true},
+		"=": {"=", false}, "+": {"=", true},
+		"^": {"[", false}, "$": {"]", false},
+		"ù": {"'", false}, "%": {"'", true},
+		"*": {`\`, false},
+		",": {"m", false}, "?": {"m", true},
+		";": {",", false}, ".": {",", true},
+		":": {".", false}, "/": {".", true},
+		"!": {"/", false}, "§": {"/", true},
+	},
+	KeyLayoutDE: {
+		"y": {"z", false}, "z": {"y", false},
+		"ß": {"-", false}, "?": {"-", true},
+		"ü": {"[", false}, "+": {"]", false}, "*": {"]", true},
+		"ö": {";", false}, "ä": {"'", false},
+		"#": {`\`, false}, "'": {`\`, true},
+		"-": {"/", false}, "_": {"/", true},
+		";": {",", true}, ":": {".", true},
+		"!": {"1", true}, `"`: {"2", true}, "§": {"3", true},
+		"$": {"4", true}, "%": {"5", true}, "&": {"6", true},
+		"/": {"7", true}, "(": {"8", true}, ")": {"9", true},
+		"=": {"0", true},
+	},
+}
+
+// scancodeKeys maps (set 1) scancodes to physical keys by US layout name.
+var scancodeKeys = map[uint16]string{
+	0x01: "escape",
+	0x02: "1", 0x03: "2", 0x04: "3", 0x05: "4", 0x06: "5",
+	0x07: "6", 0x08: "7", 0x09: "8", 0x0A: "9", 0x0B: "0",
+	0x0C: "-", 0x0D: "=", 0x0E: "backspace", 0x0F: "tab",
+	0x10: "q", 0x11: "w", 0x12: "e", 0x13: "r", 0x14: "t",
+	0x15: "y", 0x16: "u", 0x17: "i", 0x18: "o", 0x19: "p",
+	0x1A: "[", 0x1B: "]", 0x1C: "enter", 0x1D: "lctrl",
+	0x1E: "a", 0x1F: "s", 0x20: "d", 0x21: "f", 0x22: "g",
+	0x23: "h", 0x24: "j", 0x25: "k", 0x26: "l",
+	0x27: ";", 0x28: "'", 0x29: "`", 0x2A: "lshift", 0x2B: `\`,
+	0x2C: "z", 0x2D: "x", 0x2E: "c", 0x2F: "v", 0x30: "b",
+	0x31: "n", 0x32: "m",
+	0x33: ",", 0x34: ".", 0x35: "/", 0x36: "rshift",
+	0x38: "lalt", 0x39: "space", 0x3A: "capslock",
+	0x3B: "f1", 0x3C: "f2", 0x3D: "f3", 0x3E: "f4", 0x3F: "f5",
+	0x40: "f6", 0x41: "f7", 0x42: "f8", 0x43: "f9", 0x44: "f10",
+	0x57: "f11", 0x58: "f12",
+}
+
+// keyScancodes maps physical keys by US layout name to (set 1) scancodes.
+var keyScancodes = func() map[string]uint16 {
+	codes := make(map[string]uint16, len(scancodeKeys))
+	for code, key := range scancodeKeys {
+		codes[key] = code
+	}
+	return codes
+}()
+
+// NewKeyLayout creates a keyboard layout by name.
+//
+// Supported layouts are "us" (alias "qwerty"), "fr" (alias "azerty") and "de"
+// (alias "qwertz"). The "auto" layout detects the active layout of the
+// current system, defaulting to "us".
+func NewKeyLayout(name string) (*KeyLayout, error) {
+	name = strings.ToLower(name)
+	if alias, ok := keyLayoutAliases[name]; ok {
+		name = alias
+	}
+	if name == KeyLayoutAuto {
+		name = DetectKeyLayout()
+	}
+	chars, ok := keyLayouts[name]
+	if !ok {
+		return nil, ErrInvalidKeyLayout
+	}
+	return &KeyLayout{name, chars}, nil
+}
+
+// Name gets the name of kl.
+func (kl *KeyLayout) Name() string {
+	return kl.name
+}
+
+// Resolve resolves a key name to a physical key by US layout name, and to
+// whether shift must be held to produce it.
+//
+// Key names prefixed with "phys:" or "sc:" address physical keys directly.
+func (kl *KeyLayout) Resolve(key string) (phys string, shift bool) {
+	if p, ok := resolvePhysKey(key); ok {
+		return p, false
+	}
+	if kl == nil || utf8.RuneCountInString(key) != 1 {
+		return key, false
+	}
+	r, _ := utf8.DecodeRuneInString(key)
+	upper := unicode.IsUpper(r)
+	if upper {
+		key = string(unicode.ToLower(r))
+	}
+	lk, ok := kl.chars[key]
+	if !ok {
+		lk = layoutKey{key, false}
+	}
+	return lk.key, lk.shift || upper
+}
+
+// resolvePhysKey resolves key names addressing physical keys.
+func resolvePhysKey(key string) (string, bool) {
+	switch {
+	case strings.HasPrefix(key, keyPrefixPhys):
+		return strings.TrimPrefix(key, keyPrefixPhys), true
+	case strings.HasPrefix(key, keyPrefixScancode):
+		code, err := strconv.ParseUint(strings.TrimPrefix(key, keyPrefixScancode), 0, 16)
+		if err != nil {
+			return "", true
+		}
+		return scancodeKeys[uint16(code)], true
+	}
+	return key, false
+}
+
+// physKeyScancode gets the scancode of a key name addressing a known physical
+// key.
+func physKeyScancode(key string) (uint16, bool) {
+	switch {
+	case strings.HasPrefix(key, keyPrefixPhys):
+		code, ok := keyScancodes[strings.TrimPrefix(key, keyPrefixPhys)]
+		return code, ok
+	case strings.HasPrefix(key, keyPrefixScancode):
+		code, err := strconv.ParseUint(strings.TrimPrefix(key, keyPrefixScancode), 0, 16)
+		if err != nil {
+			return 0, false
+		}
+		_, ok := scancodeKeys[uint16(code)]
+		return uint16(code), ok
+	}
+	return 0, false
+}
+
+// isValidKeyName checks whether key is a valid key name.
+func isValidKeyName(key string) bool {
+	phys, ok := resolvePhysKey(key)
+	return phys != "" || (!ok && key != "")
+}
+
+// DetectKeyLayout detects the name of the active keyboard layout of the
+// current system, defaulting to "us".
+func DetectKeyLayout() string {
+	var out []byte
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		out, err = exec.Command(
+			"defaults", "read", "com.apple.HIToolbox",
+			"AppleCurrentKeyboardLayoutInputSourceID",
+		).Output()
+	case "linux":
+		out, err = exec.Command("setxkbmap", "-query").Output()
+	default:
+		return KeyLayoutUS
+	}
+	if err != nil {
+		return KeyLayoutUS
+	}
+	return parseKeyLayoutName(string(out))
+}
+
+// parseKeyLayoutName parses the output of a layout detection command.
+func parseKeyLayoutName(out string) string {
+	name := strings.TrimSpace(out)
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "layout:") {
+			name = strings.TrimSpace(strings.TrimPrefix(line, "layout:"))
+			name = strings.SplitN(name, ",", 2)[0]
+		}
+	}
+	name = strings.ToLower(strings.TrimPrefix(name, "com.apple.keylayout."))
+	switch name {
+	case KeyLayoutFR, "french", "french-pc":
+		return KeyLayoutFR
+	case KeyLayoutDE, "at", "german", "austrian":
+		return KeyLayoutDE
+	}
+	return KeyLayoutUS
+}
+
+var (
+	sharedKeyLayout   *KeyLayout
+	sharedKeyLayoutMx sync.RWMutex
+)
+
+// SetKeyLayout sets the keyboard layout of the default driver.
+//
+// Layouts are meant for drivers sending US layout keycodes only; the robotgo
+// driver resolves characters through the active layout of the system itself.
+// A nil layout (the default) disables layout resolution.
+func SetKeyLayout(kl *KeyLayout) {
+	sharedKeyLayoutMx.Lock()
+	defer sharedKeyLayoutMx.Unlock()
+	sharedKeyLayout = kl
+}
+
+func getKeyLayout() *KeyLayout {
+	sharedKeyLayoutMx.RLock()
+	defer sharedKeyLayoutMx.RUnlock()
+	return sharedKeyLayout
+}
+
+// LayoutDriver implements a system input/output driver resolving keys through
+// a keyboard layout before passing them on to a US layout driver, i.e. a
+// driver sending keys by their US layout keycodes.
+//
+// Without a layout, keys are passed on as-is.
+type LayoutDriver struct {
+	Driver
+	layout  *KeyLayout
+	dynamic bool
+}
+
+// NewLayoutDriver creates a new driver resolving keys through layout.
+func NewLayoutDriver(drv Driver, layout *KeyLayout) *LayoutDriver {
+	return &LayoutDriver{Driver: drv, layout: layout}
+}
+
+func (d *LayoutDriver) getLayout() *KeyLayout {
+	if d.dynamic {
+		return getKeyLayout()
+	}
+	return d.layout
+}
+
+// KeyTap triggers a short key press & release while holding modifiers.
+func (d *LayoutDriver) KeyTap(key string, modifiers ...string) {
+	kl := d.getLayout()
+	if kl == nil {
+		d.Driver.KeyTap(key, modifiers...)
+		return
+	}
+	key, shift := kl.Resolve(key)
+	mods := make([]string, 0, len(modifiers)+1)
+	for _, m := range modifiers {
+		m, _ = kl.Resolve(m)
+		if m == "shift" {
+			shift = false
+		}
+		mods = append(mods, m)
+	}
+	if shift {
+		mods = append(mods, "shift")
+	}
+	d.Driver.KeyTap(key, mods...)
+}
+
+// KeyToggle presses or releases a key.
+func (d *LayoutDriver) KeyToggle(key string, down bool) {
+	kl := d.getLayout()
+	if kl == nil {
+		d.Driver.KeyToggle(key, down)
+		return
+	}
+	key, shift := kl.Resolve(key)
+	if shift && down {
+		d.Driver.KeyToggle("shift", true)
+	}
+	d.Driver.KeyToggle(key, down)
+	if shift && !down {
+		d.Driver.KeyToggle("shift", false)
+	}
+}
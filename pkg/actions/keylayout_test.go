@@ -0,0 +1,134 @@
+package actions_test
+
+import (
+	"testing"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyLayoutResolve(t *testing.T) {
+	t.Parallel()
+
+	type phys struct {
+		key   string
+		shift bool
+	}
+
+	tests := []struct {
+		layout string
+		keys   map[string]phys
+	}{
+		{"us", map[string]phys{
+			"a":     {"a", false},
+			"z":     {"z", false},
+			"Z":     {"z", true},
+			"[":     {"[", false},
+			"enter": {"enter", false},
+		}},
+		{"azerty", map[string]phys{
+			"a":       {"q", false},
+			"q":       {"a", false},
+			"z":       {"w", false},
+			"W":       {"z", true},
+			"m":       {";", false},
+			"1":       {"1", true},
+			"&":       {"1", false},
+			",":       {"m", false},
+			"!":       {"/", false},
+			"x":       {"x", false},
+			"f1":      {"f1", false},
+			"phys:z":  {"z", false},
+			"sc:0x11": {"w", false},
+		}},
+		{"qwertz", map[string]phys{
+			"z":       {"y", false},
+			"y":       {"z", false},
+			"Y":       {"z", true},
+			"ö":       {";", false},
+			"/":       {"7", true},
+			"-":       {"/", false},
+			"a":       {"a", false},
+			"phys:y":  {"y", false},
+			"sc:0x2C": {"z", false},
+		}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.layout, func(t *testing.T) {
+			t.Parallel()
+			kl, err := actions.NewKeyLayout(tc.layout)
+			require.NoError(t, err)
+			for key, want := range tc.keys {
+				gotKey, gotShift := kl.Resolve(key)
+				assert.Equal(t, want, phys{gotKey, gotShift}, "key \"%s\"", key)
+			}
+		})
+	}
+}
+
+func TestNewKeyLayout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		wantName string
+		wantOk   bool
+	}{
+		{"us", "us", true},
+		{"QWERTY", "us", true},
+		{"fr", "fr", true},
+		{"azerty", "fr", true},
+		{"de", "de", true},
+		{"qwertz", "de", true},
+		{"dvorak", "", false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			kl, err := actions.NewKeyLayout(tc.name)
+			if !tc.wantOk {
+				assert.Nil(t, kl)
+				assert.ErrorIs(t, err, actions.ErrInvalidKeyLayout)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantName, kl.Name())
+		})
+	}
+
+	kl, err := actions.NewKeyLayout("auto")
+	require.NoError(t, err)
+	assert.NotEmpty(t, kl.Name())
+}
+
+func TestLayoutDriver(t *testing.T) {
+	t.Parallel()
+
+	kl, err := actions.NewKeyLayout("azerty")
+	require.NoError(t, err)
+	fake := new(actions.FakeDriver)
+	drv := actions.NewLayoutDriver(fake, kl)
+
+	drv.KeyTap("z", "cmd")
+	drv.KeyTap("Z", "cmd", "shift")
+	drv.KeyTap("1")
+	assert.Equal(t, [][]string{
+		{"cmd", "w"},
+		{"cmd", "shift", "w"},
+		{"shift", "1"},
+	}, fake.Taps())
+
+	drv.KeyToggle("2", true)
+	drv.KeyToggle("2", false)
+	drv.KeyToggle("a", true)
+	assert.Equal(t, []string{
+		"down shift",
+		"down 2",
+		"up 2",
+		"up shift",
+		"down q",
+	}, fake.KeyEvents())
+}
@@ -0,0 +1,144 @@
+package actions
+
+import (
+	"context"
+	"sync"
+)
+
+// KeyHolder presses and releases keys while tracking which keys are held.
+//
+// Keys held by several combos (e.g. a shared modifier) are released once the
+// last combo holding them is released.
+type KeyHolder struct {
+	drv  Driver
+	held map[string]int
+	mx   sync.Mutex
+}
+
+// NewKeyHolder creates a new key holder based on a custom driver.
+//
+// When drv is nil, the default driver is used.
+func NewKeyHolder(drv Driver) *KeyHolder {
+	return &KeyHolder{
+		drv:  driverOrDefault(drv),
+		held: make(map[string]int),
+	}
+}
+
+var (
+	sharedKeyHolder     *KeyHolder
+	sharedKeyHolderOnce sync.Once
+)
+
+// DefaultKeyHolder gets the shared default key holder.
+func DefaultKeyHolder() *KeyHolder {
+	sharedKeyHolderOnce.Do(func() {
+		sharedKeyHolder = NewKeyHolder(nil)
+	})
+	return sharedKeyHolder
+}
+
+// ReleaseHeldKeys releases all keys held via the default key holder.
+func ReleaseHeldKeys() {
+	DefaultKeyHolder().ReleaseAll()
+}
+
+// Down presses modifiers and key, in order, and holds them.
+func (kh *KeyHolder) Down(key string, modifiers ...string) {
+	kh.mx.Lock()
+	defer kh.mx.Unlock()
+	for _, k := range modifiers {
+		kh.press(k)
+	}
+	kh.press(key)
+}
+
+func (kh *KeyHolder) press(key string) {
+	kh.held[key]++
+	if kh.held[key] == 1 {
+		kh.drv.KeyToggle(key, true)
+	}
+}
+
+// Up releases key and modifiers, in reverse order.
+//
+// Keys that are not held are skipped.
+func (kh *KeyHolder) Up(key string, modifiers ...string) {
+	kh.mx.Lock()
+	defer kh.mx.Unlock()
+	kh.release(key)
+	for i := len(modifiers) - 1; i >= 0; i-- {
+		kh.release(modifiers[i])
+	}
+}
+
+func (kh *KeyHolder) release(key string) {
+	n, ok := kh.held[key]
+	if !ok {
+		return
+	}
+	if n > 1 {
+		kh.held[key] = n - 1
+		return
+	}
+	delete(kh.held, key)
+	kh.drv.KeyToggle(key, false)
+}
+
+// Held gets the number of keys currently held.
+func (kh *KeyHolder) Held() int {
+	kh.mx.Lock()
+	defer kh.mx.Unlock()
+	return len(kh.held)
+}
+
+// ReleaseAll releases all held keys.
+func (kh *KeyHolder) ReleaseAll() {
+	kh.mx.Lock()
+	defer kh.mx.Unlock()
+	for key := range kh.held {
+		delete(kh.held, key)
+		kh.drv.KeyToggle(key, false)
+	}
+}
+
+// NewTap creates an action that taps a key while holding modifiers.
+//
+// When drv is nil, the default driver is used.
+func NewTap(drv Driver, key string, modifiers ...string) Action {
+	drv = driverOrDefault(drv)
+	tap := func(context.Context, Trigger) error {
+		drv.KeyTap(key, modifiers...)
+		return nil
+	}
+	return tap
+}
+
+// NewKeyDown creates an action that presses and holds modifiers and key.
+//
+// When kh is nil, the default key holder is used.
+func NewKeyDown(kh *KeyHolder, key string, modifiers ...string) Action {
+	down := func(context.Context, Trigger) error {
+		keyHolderOrDefault(kh).Down(key, modifiers...)
+		return nil
+	}
+	return down
+}
+
+// NewKeyUp creates an action that releases key and modifiers.
+//
+// When kh is nil, the default key holder is used.
+func NewKeyUp(kh *KeyHolder, key string, modifiers ...string) Action {
+	up := func(context.Context, Trigger) error {
+		keyHolderOrDefault(kh).Up(key, modifiers...)
+		return nil
+	}
+	return up
+}
+
+func keyHolderOrDefault(kh *KeyHolder) *KeyHolder {
+	if kh == nil {
+		return DefaultKeyHolder()
+	}
+	return kh
+}
@@ -0,0 +1,55 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyHolder(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	kh := actions.NewKeyHolder(drv)
+
+	actions.NewKeyDown(kh, "tab", "cmd")(context.Background(), actions.Trigger{})
+	assert.Equal(t, 2, kh.Held())
+	actions.NewKeyDown(kh, "cmd")(context.Background(), actions.Trigger{})
+	actions.NewKeyUp(kh, "tab", "cmd")(context.Background(), actions.Trigger{})
+	assert.Equal(t, 1, kh.Held())
+	actions.NewKeyUp(kh, "cmd")(context.Background(), actions.Trigger{})
+	assert.Equal(t, 0, kh.Held())
+	actions.NewKeyUp(kh, "cmd")(context.Background(), actions.Trigger{})
+
+	assert.Equal(t, []string{
+		"down cmd",
+		"down tab",
+		"up tab",
+		"up cmd",
+	}, drv.KeyEvents())
+}
+
+func TestKeyHolderReleaseAll(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	kh := actions.NewKeyHolder(drv)
+
+	kh.Down("a", "shift")
+	kh.Down("b", "shift")
+	kh.ReleaseAll()
+	assert.Equal(t, 0, kh.Held())
+	assert.ElementsMatch(t, []string{
+		"down shift",
+		"down a",
+		"down b",
+		"up shift",
+		"up a",
+		"up b",
+	}, drv.KeyEvents())
+
+	kh.Up("a", "shift")
+	assert.Len(t, drv.KeyEvents(), 6)
+}
@@ -0,0 +1,218 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key sequence errors raised by package actions.
+var (
+	ErrInvalidKeySequence = errors.New("key sequence is invalid")
+)
+
+// keyStep holds a single step of a key sequence: typed text, a repeated key
+// tap, or a pause.
+type keyStep struct {
+	text      string
+	key       string
+	modifiers []string
+	repeat    int
+	sleep     time.Duration
+}
+
+// KeySequence holds a parsed key sequence.
+type KeySequence struct {
+	steps []keyStep
+}
+
+// ParseKeySequence parses a key sequence.
+//
+// A key sequence consists of literal text and of brace-enclosed commands:
+//   - "{key}", "{mod+…+key}": taps a key while holding modifiers, e.g.
+//     "{enter}" or "{cmd+shift+z}".
+//   - "{key n}", "{mod+…+key n}": taps a key n times, e.g. "{tab 3}".
+//   - "{sleep ms}": pauses for a number of milliseconds.
+//   - "{lbrace}", "{rbrace}": types a literal "{" or "}".
+func ParseKeySequence(seq string) (*KeySequence, error) {
+	ks := &KeySequence{}
+	var text strings.Builder
+	flushText := func() {
+		if text.Len() > 0 {
+			ks.steps = append(ks.steps, keyStep{text: text.String()})
+			text.Reset()
+		}
+	}
+	for rest := seq; rest != ""; {
+		start := strings.IndexByte(rest, '{')
+		if start == -1 {
+			text.WriteString(rest)
+			break
+		}
+		text.WriteString(rest[:start])
+		end := strings.IndexByte(rest[start:], '}')
+		if end == -1 {
+			return nil, ErrInvalidKeySequence
+		}
+		cmd := rest[start+1 : start+end]
+		rest = rest[start+end+1:]
+
+		switch cmd {
+		case "lbrace":
+			text.WriteByte('{')
+			continue
+		case "rbrace":
+			text.WriteByte('}')
+			continue
+		}
+		step, err := parseKeyStep(cmd)
+		if err != nil {
+			return nil, err
+		}
+		flushText()
+		ks.steps = append(ks.steps, step)
+	}
+	flushText()
+	return ks, nil
+}
+
+func parseKeyStep(cmd string) (keyStep, error) {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 || len(fields) > 2 {
+		return keyStep{}, ErrInvalidKeySequence
+	}
+	n := 1
+	if len(fields) == 2 {
+		var err error
+		if n, err = strconv.Atoi(fields[1]); err != nil || n < 1 {
+			return keyStep{}, ErrInvalidKeySequence
+		}
+	}
+	if fields[0] == "sleep" {
+		if len(fields) != 2 {
+			return keyStep{}, ErrInvalidKeySequence
+		}
+		return keyStep{sleep: time.Duration(n) * time.Millisecond}, nil
+	}
+	combo := strings.Split(fields[0], "+")
+	for _, k := range combo {
+		if !isValidKeyName(k) {
+			return keyStep{}, ErrInvalidKeySequence
+		}
+	}
+	l := len(combo)
+	return keyStep{
+		key:       combo[l-1],
+		modifiers: combo[:l-1],
+		repeat:    n,
+	}, nil
+}
+
+// Run types out ks via drv.
+//
+// The charDelay pauses between typed characters, and the keyDelay pauses after
+// each key tap. Typing stops once ctx is cancelled.
+func (ks *KeySequence) Run(
+	ctx context.Context,
+	drv Driver,
+	charDelay, keyDelay time.Duration,
+) error {
+	for _, step := range ks.steps {
+		switch {
+		case step.text != "":
+			if err := typeText(ctx, drv, step.text, charDelay); err != nil {
+				return err
+			}
+		case step.key != "":
+			for i := 0; i < step.repeat; i++ {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				drv.KeyTap(step.key, step.modifiers...)
+				if err := sleepContext(ctx, keyDelay); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := sleepContext(ctx, step.sleep); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func typeText(
+	ctx context.Context,
+	drv Driver,
+	text string,
+	charDelay time.Duration,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if charDelay <= 0 {
+		drv.TypeText(text)
+		return nil
+	}
+	for i, r := range []rune(text) {
+		if i > 0 {
+			if err := sleepContext(ctx, charDelay); err != nil {
+				return err
+			}
+		}
+		drv.TypeText(string(r))
+	}
+	return nil
+}
+
+// TypeOptions holds io:type options.
+type TypeOptions struct {
+	// Keys enables key sequences (see ParseKeySequence).
+	Keys bool
+	// CharDelay pauses between typed characters.
+	CharDelay time.Duration
+	// KeyDelay pauses after each key tap of a key sequence.
+	KeyDelay time.Duration
+}
+
+// NewType creates an action that types out text.
+//
+// When drv is nil, the default driver is used.
+func NewType(drv Driver, text string, opts TypeOptions) (Action, error) {
+	drv = driverOrDefault(drv)
+	if !opts.Keys {
+		write := func(ctx context.Context, _ Trigger) error {
+			return typeText(ctx, drv, text, opts.CharDelay)
+		}
+		return write, nil
+	}
+	ks, err := ParseKeySequence(text)
+	if err != nil {
+		return nil, err
+	}
+	write := func(ctx context.Context, _ Trigger) error {
+		return ks.Run(ctx, drv, opts.CharDelay, opts.KeyDelay)
+	}
+	return write, nil
+}
+
+func parseTypeOptions(arg interface{}) (TypeOptions, bool) {
+	var to TypeOptions
+	opts, ok := parseActionOptions(arg, "keys", "char-delay", "key-delay")
+	if !ok {
+		return to, false
+	}
+	if to.Keys, ok = opts.bool("keys", false); !ok {
+		return to, false
+	}
+	if to.CharDelay, ok = opts.duration("char-delay", 0); !ok {
+		return to, false
+	}
+	if to.KeyDelay, ok = opts.duration("key-delay", 0); !ok {
+		return to, false
+	}
+	return to, true
+}
@@ -0,0 +1,139 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeySequence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		seq       string
+		wantTyped string
+		wantTaps  [][]string
+		wantOk    bool
+	}{
+		{"", "", nil, true},
+		{"Hello", "Hello", nil, true},
+		{"{enter}", "", [][]string{{"enter"}}, true},
+		{"{cmd+a}{backspace}Hello{enter}", "Hello", [][]string{
+			{"cmd", "a"},
+			{"backspace"},
+			{"enter"},
+		}, true},
+		{"a{tab 3}b", "ab", [][]string{{"tab"}, {"tab"}, {"tab"}}, true},
+		{"{ctrl+shift+z 2}", "", [][]string{
+			{"ctrl", "shift", "z"},
+			{"ctrl", "shift", "z"},
+		}, true},
+		{"a{sleep 1}b", "ab", nil, true},
+		{"{lbrace}x{rbrace}", "{x}", nil, true},
+		{"a}b", "a}b", nil, true},
+
+		{"{", "", nil, false},
+		{"{enter", "", nil, false},
+		{"{}", "", nil, false},
+		{"{ }", "", nil, false},
+		{"{cmd+}", "", nil, false},
+		{"{+a}", "", nil, false},
+		{"{tab 0}", "", nil, false},
+		{"{tab x}", "", nil, false},
+		{"{tab 1 2}", "", nil, false},
+		{"{sleep}", "", nil, false},
+		{"{sleep -1}", "", nil, false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.seq, func(t *testing.T) {
+			t.Parallel()
+			ks, err := actions.ParseKeySequence(tc.seq)
+			if !tc.wantOk {
+				assert.Nil(t, ks)
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			drv := new(actions.FakeDriver)
+			require.NoError(t, ks.Run(context.Background(), drv, 0, 0))
+			assert.Equal(t, tc.wantTyped, drv.Typed())
+			assert.Equal(t, tc.wantTaps, nilIfEmpty(drv.Taps()))
+		})
+	}
+}
+
+func nilIfEmpty(taps [][]string) [][]string {
+	if len(taps) == 0 {
+		return nil
+	}
+	return taps
+}
+
+func TestKeySequenceRunDelays(t *testing.T) {
+	t.Parallel()
+
+	ks, err := actions.ParseKeySequence("ab{enter}{sleep 20}")
+	require.NoError(t, err)
+
+	drv := new(actions.FakeDriver)
+	start := time.Now()
+	require.NoError(t, ks.Run(context.Background(), drv, time.Millisecond*10, time.Millisecond*30))
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*60)
+	assert.Equal(t, "ab", drv.Typed())
+}
+
+func TestKeySequenceRunCancel(t *testing.T) {
+	t.Parallel()
+
+	ks, err := actions.ParseKeySequence("a{sleep 5000}b")
+	require.NoError(t, err)
+
+	drv := new(actions.FakeDriver)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	start := time.Now()
+	err = ks.Run(ctx, drv, 0, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, "a", drv.Typed())
+}
+
+func TestNewType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		text      string
+		opts      actions.TypeOptions
+		wantTyped string
+		wantTaps  int
+		wantOk    bool
+	}{
+		{"types literal text", "{enter}", actions.TypeOptions{}, "{enter}", 0, true},
+		{"types per character", "abc", actions.TypeOptions{CharDelay: time.Millisecond}, "abc", 0, true},
+		{"types key sequence", "a{enter}", actions.TypeOptions{Keys: true}, "a", 1, true},
+		{"rejects invalid key sequence", "a{enter", actions.TypeOptions{Keys: true}, "", 0, false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			drv := new(actions.FakeDriver)
+			write, err := actions.NewType(drv, tc.text, tc.opts)
+			if !tc.wantOk {
+				assert.Nil(t, write)
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, write(context.Background(), actions.Trigger{}))
+			assert.Equal(t, tc.wantTyped, drv.Typed())
+			assert.Len(t, drv.Taps(), tc.wantTaps)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package actions
+
+import (
+	"sync"
+
+	"github.com/echocrow/Mouser/pkg/log"
+)
+
+var (
+	sharedLogger   log.Logger
+	sharedLoggerMx sync.RWMutex
+)
+
+// SetLogger sets the logger of actions, e.g. of confirmation messages, plugin
+// logs and logged command output.
+//
+// A nil logger restores the default loggers per topic.
+func SetLogger(l log.Logger) {
+	sharedLoggerMx.Lock()
+	defer sharedLoggerMx.Unlock()
+	sharedLogger = l
+}
+
+// topicLogger implements a logger of a topic, which defers to the logger set
+// via SetLogger, if any.
+type topicLogger struct {
+	def log.Logger
+}
+
+// newTopicLogger creates a new logger of topic name.
+func newTopicLogger(name string) topicLogger {
+	return topicLogger{log.New(name)}
+}
+
+// Printf logs a formatted message.
+func (l topicLogger) Printf(format string, args ...interface{}) {
+	sharedLoggerMx.RLock()
+	shared := sharedLogger
+	sharedLoggerMx.RUnlock()
+	if shared != nil {
+		shared.Printf(format, args...)
+		return
+	}
+	l.def.Printf(format, args...)
+}
@@ -0,0 +1,185 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Notification urgency levels.
+const (
+	UrgencyLow      = "low"
+	UrgencyNormal   = "normal"
+	UrgencyCritical = "critical"
+)
+
+// Freedesktop.org notification service names.
+const (
+	dbusNotifyDest   = "org.freedesktop.Notifications"
+	dbusNotifyPath   = "/org/freedesktop/Notifications"
+	dbusNotifyMethod = dbusNotifyDest + ".Notify"
+)
+
+// notifyAppName is the application name sent with notifications.
+const notifyAppName = "Mouser"
+
+// Notification errors raised by package actions.
+var (
+	ErrUnsupportedNotify = errors.New("notifications are not supported on this platform")
+	ErrInvalidUrgency    = errors.New("notification urgency is invalid")
+)
+
+// Notification holds a desktop notification.
+type Notification struct {
+	Title string
+	Body  string
+	// Urgency is the urgency level, i.e. one of the Urgency* constants;
+	// defaults to UrgencyNormal.
+	Urgency string
+}
+
+// Notifier describes a desktop notification provider.
+//go:generate mockery --name "Notifier"
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+var (
+	defaultNotifier     Notifier
+	defaultNotifierOnce sync.Once
+)
+
+// DefaultNotifier gets the default desktop notification provider of the
+// current platform.
+//
+// Notifications are sent via the freedesktop.org notification service
+// (D-Bus) on Linux, and via osascript on macOS.
+func DefaultNotifier() Notifier {
+	defaultNotifierOnce.Do(func() {
+		switch runtime.GOOS {
+		case "darwin":
+			defaultNotifier = osascriptNotifier{}
+		case "linux":
+			defaultNotifier = NewDBusNotifier(nil)
+		default:
+			defaultNotifier = unsupportedNotifier{}
+		}
+	})
+	return defaultNotifier
+}
+
+// CheckUrgency checks whether urgency is a valid urgency level.
+func CheckUrgency(urgency string) error {
+	_, err := dbusUrgency(urgency)
+	return err
+}
+
+// dbusUrgency gets the freedesktop.org urgency level of urgency.
+func dbusUrgency(urgency string) (byte, error) {
+	switch urgency {
+	case UrgencyLow:
+		return 0, nil
+	case UrgencyNormal, "":
+		return 1, nil
+	case UrgencyCritical:
+		return 2, nil
+	}
+	return 0, fmt.Errorf("%w: \"%s\"", ErrInvalidUrgency, urgency)
+}
+
+// DBusNotifier sends notifications via the freedesktop.org notification
+// service.
+type DBusNotifier struct {
+	conn *dbus.Conn
+	mx   sync.Mutex
+}
+
+// NewDBusNotifier creates a new D-Bus notifier sending notifications via conn.
+//
+// A nil conn connects to the session bus on the first notification.
+func NewDBusNotifier(conn *dbus.Conn) *DBusNotifier {
+	return &DBusNotifier{conn: conn}
+}
+
+func (dn *DBusNotifier) connect() (*dbus.Conn, error) {
+	dn.mx.Lock()
+	defer dn.mx.Unlock()
+	if dn.conn == nil {
+		conn, err := dbus.ConnectSessionBus()
+		if err != nil {
+			return nil, err
+		}
+		dn.conn = conn
+	}
+	return dn.conn, nil
+}
+
+// Notify sends notification n.
+func (dn *DBusNotifier) Notify(n Notification) error {
+	urgency, err := dbusUrgency(n.Urgency)
+	if err != nil {
+		return err
+	}
+	conn, err := dn.connect()
+	if err != nil {
+		return err
+	}
+	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(urgency)}
+	return conn.Object(dbusNotifyDest, dbusNotifyPath).Call(
+		dbusNotifyMethod, 0,
+		notifyAppName, uint32(0), "", n.Title, n.Body, []string{}, hints, int32(-1),
+	).Err
+}
+
+// osascriptNotifier sends notifications via AppleScript.
+type osascriptNotifier struct{}
+
+func (osascriptNotifier) Notify(n Notification) error {
+	if err := CheckUrgency(n.Urgency); err != nil {
+		return err
+	}
+	script := "display notification " + appleScriptQuote(n.Body) +
+		" with title " + appleScriptQuote(n.Title)
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// appleScriptEscaper escapes the special characters of AppleScript strings.
+var appleScriptEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// appleScriptQuote gets str as an AppleScript string literal.
+//
+// Unlike Go string literals, AppleScript string literals only escape
+// backslashes and double quotes; other characters (e.g. newlines or non-ASCII
+// characters) are kept as-is.
+func appleScriptQuote(str string) string {
+	return `"` + appleScriptEscaper.Replace(str) + `"`
+}
+
+// unsupportedNotifier fails to send any notifications.
+type unsupportedNotifier struct{}
+
+func (unsupportedNotifier) Notify(Notification) error {
+	return ErrUnsupportedNotify
+}
+
+// NewNotify creates a new action showing a desktop notification via n.
+//
+// A nil n uses the default notifier (see DefaultNotifier).
+func NewNotify(n Notifier, notification Notification) (Action, error) {
+	if err := CheckUrgency(notification.Urgency); err != nil {
+		return nil, err
+	}
+	return func(context.Context, Trigger) error {
+		nn := n
+		if nn == nil {
+			nn = DefaultNotifier()
+		}
+		return nn.Notify(notification)
+	}, nil
+}
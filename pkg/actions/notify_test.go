@@ -0,0 +1,144 @@
+package actions_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/echocrow/Mouser/pkg/actions/mocks"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckUrgency(t *testing.T) {
+	t.Parallel()
+
+	for _, urgency := range []string{"", "low", "normal", "critical"} {
+		assert.NoError(t, actions.CheckUrgency(urgency), urgency)
+	}
+	assert.ErrorIs(t, actions.CheckUrgency("foo"), actions.ErrInvalidUrgency)
+}
+
+func TestNewNotify(t *testing.T) {
+	n := actions.Notification{Title: "Foo", Body: "Bar", Urgency: actions.UrgencyLow}
+
+	nr := new(mocks.Notifier)
+	nr.On("Notify", n).Return(nil).Once()
+	a, err := actions.NewNotify(nr, n)
+	require.NoError(t, err)
+	assert.NoError(t, a(context.Background(), actions.Trigger{}))
+	nr.AssertExpectations(t)
+
+	nr.On("Notify", n).Return(errors.New("nope")).Once()
+	assert.EqualError(t, a(context.Background(), actions.Trigger{}), "nope")
+
+	_, err = actions.NewNotify(nr, actions.Notification{Urgency: "foo"})
+	assert.ErrorIs(t, err, actions.ErrInvalidUrgency)
+}
+
+type testNotification struct {
+	appName string
+	title   string
+	body    string
+	urgency byte
+}
+
+type testNotifyServer struct {
+	ch chan testNotification
+}
+
+func (s testNotifyServer) Notify(
+	appName string,
+	replacesID uint32,
+	icon, title, body string,
+	actions []string,
+	hints map[string]dbus.Variant,
+	timeout int32,
+) (uint32, *dbus.Error) {
+	urgency, _ := hints["urgency"].Value().(byte)
+	s.ch <- testNotification{appName, title, body, urgency}
+	return 1, nil
+}
+
+// startTestSessionBus starts a private D-Bus session bus, and gets its address.
+func startTestSessionBus(t *testing.T) string {
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not found")
+	}
+
+	cmd := exec.Command(
+		daemon, "--session", "--nofork", "--print-address",
+		"--address=unix:dir="+t.TempDir(),
+	)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	return strings.TrimSpace(addr)
+}
+
+func TestDBusNotifier(t *testing.T) {
+	t.Parallel()
+
+	addr := startTestSessionBus(t)
+
+	srvConn, err := dbus.Connect(addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { srvConn.Close() })
+	srv := testNotifyServer{make(chan testNotification, 1)}
+	require.NoError(t, srvConn.Export(
+		srv,
+		"/org/freedesktop/Notifications",
+		"org.freedesktop.Notifications",
+	))
+	reply, err := srvConn.RequestName("org.freedesktop.Notifications", 0)
+	require.NoError(t, err)
+	require.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+
+	conn, err := dbus.Connect(addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	dn := actions.NewDBusNotifier(conn)
+
+	tests := []struct {
+		n    actions.Notification
+		want testNotification
+	}{
+		{
+			actions.Notification{Title: "Foo", Body: "Bar"},
+			testNotification{"Mouser", "Foo", "Bar", 1},
+		},
+		{
+			actions.Notification{Title: "Fizz", Body: "Buzz", Urgency: actions.UrgencyCritical},
+			testNotification{"Mouser", "Fizz", "Buzz", 2},
+		},
+		{
+			actions.Notification{Title: "Low", Urgency: actions.UrgencyLow},
+			testNotification{"Mouser", "Low", "", 0},
+		},
+	}
+	for _, tc := range tests {
+		require.NoError(t, dn.Notify(tc.n))
+		select {
+		case got := <-srv.ch:
+			assert.Equal(t, tc.want, got)
+		case <-time.After(time.Second):
+			t.Fatal("notification not received")
+		}
+	}
+
+	assert.ErrorIs(t, dn.Notify(actions.Notification{Urgency: "foo"}), actions.ErrInvalidUrgency)
+}
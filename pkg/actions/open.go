@@ -0,0 +1,151 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// desktopEntryExt is the file extension of freedesktop.org desktop entries.
+const desktopEntryExt = ".desktop"
+
+// Open errors raised by package actions.
+var (
+	ErrUnsupportedOpen       = errors.New("opening is not supported on this platform")
+	ErrUnsupportedOpenerArgs = errors.New("opener arguments are not supported on this platform")
+)
+
+// Open holds the options of an open action.
+type Open struct {
+	// Target is the path of a file or application, a URL, or (on Linux) a
+	// desktop entry, i.e. a desktop file path or ID such as "firefox.desktop".
+	Target string
+	// App is the application to open Target with; defaults to the default
+	// application of Target. On Linux, App may be an executable or a desktop
+	// entry.
+	App string
+	// OpenerArgs holds extra arguments passed to the platform's opener, e.g.
+	// "-g" to open in the background. Only the macOS opener takes extra
+	// arguments.
+	OpenerArgs []string
+	// Args holds extra arguments passed to the opened application, if
+	// supported.
+	Args []string
+}
+
+// Command gets the command line opening o on the platform goos, using the
+// platform's opener: "open" on macOS, "xdg-open" (or "gtk-launch" and
+// "gio launch" for desktop entries) on Linux, and ShellExecute (via
+// "rundll32") on Windows.
+//
+// Applications, and targets receiving arguments on Windows, are run directly
+// instead.
+func (o Open) Command(goos string) ([]string, error) {
+	if goos != "darwin" && len(o.OpenerArgs) > 0 {
+		return nil, ErrUnsupportedOpenerArgs
+	}
+	switch goos {
+	case "darwin":
+		if isDesktopEntry(o.Target) || isDesktopEntry(o.App) {
+			return nil, ErrUnsupportedOpen
+		}
+		cmd := append([]string{"open"}, o.OpenerArgs...)
+		if o.App != "" {
+			cmd = append(cmd, "-a", o.App)
+		}
+		cmd = append(cmd, o.Target)
+		if len(o.Args) > 0 {
+			cmd = append(cmd, "--args")
+			cmd = append(cmd, o.Args...)
+		}
+		return cmd, nil
+	case "linux":
+		if o.App == "" && isDesktopEntry(o.Target) {
+			return append(desktopLaunchCommand(o.Target), o.Args...), nil
+		}
+		if o.App == "" {
+			return []string{"xdg-open", o.Target}, nil
+		}
+		if isDesktopEntry(o.App) {
+			cmd := append(desktopLaunchCommand(o.App), o.Target)
+			return append(cmd, o.Args...), nil
+		}
+		// Executables are run directly, without an opener.
+		cmd := append([]string{o.App}, o.Args...)
+		return append(cmd, o.Target), nil
+	case "windows":
+		// "start" is avoided, as "cmd" would re-parse the target, e.g. at "&".
+		if o.App != "" {
+			return append([]string{o.App, o.Target}, o.Args...), nil
+		}
+		if len(o.Args) > 0 {
+			return append([]string{o.Target}, o.Args...), nil
+		}
+		return []string{"rundll32", "url.dll,FileProtocolHandler", o.Target}, nil
+	}
+	return nil, ErrUnsupportedOpen
+}
+
+// desktopLaunchCommand gets the command launching a desktop entry.
+func desktopLaunchCommand(entry string) []string {
+	if strings.ContainsRune(entry, '/') {
+		return []string{"gio", "launch", entry}
+	}
+	return []string{"gtk-launch", entry}
+}
+
+// isDesktopEntry checks whether target refers to a desktop entry.
+func isDesktopEntry(target string) bool {
+	return strings.HasSuffix(target, desktopEntryExt) && !isURL(target)
+}
+
+// isURL checks whether target is a URL, e.g. "https://example.com" or
+// "mailto:me@example.com".
+func isURL(target string) bool {
+	u, err := url.Parse(target)
+	// Single-letter schemes are Windows drive letters.
+	return err == nil && len(u.Scheme) > 1
+}
+
+// NewOpen creates a new action opening a file, URL or application.
+//
+// Failures to start the opener are returned; the opener runs supervised (see
+// DefaultSupervisor).
+func NewOpen(o Open) (Action, error) {
+	cmdLine, err := o.Command(runtime.GOOS)
+	if err != nil {
+		return nil, err
+	}
+	open := func(context.Context, Trigger) error {
+		name := cmdLine[0]
+		args := cmdLine[1:]
+		if name == "xdg-open" {
+			if _, err := exec.LookPath(name); err != nil {
+				name, args = "gio", append([]string{"open"}, args...)
+			}
+		}
+		cmd := exec.Command(name, args...)
+		return DefaultSupervisor().Start("", PolicyParallel, cmd)
+	}
+	return open, nil
+}
+
+// parseOpen parses the arguments of an open action.
+func parseOpen(target string, openerArgs []string, arg interface{}) (Open, bool) {
+	o := Open{Target: target, OpenerArgs: openerArgs}
+	if arg == nil {
+		return o, true
+	}
+	opts, ok := parseActionOptions(arg, "app", "app-args")
+	if !ok {
+		return o, false
+	}
+	if o.App, ok = opts.string("app", ""); !ok {
+		return o, false
+	}
+	o.Args, ok = opts.stringList("app-args")
+	return o, ok
+}
@@ -0,0 +1,52 @@
+package actions_test
+
+import (
+	"testing"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenCommand(t *testing.T) {
+	t.Parallel()
+
+	type o = actions.Open
+	tests := []struct {
+		goos    string
+		o       o
+		want    []string
+		wantErr error
+	}{
+		{"darwin", o{Target: "/tmp/a.txt"}, []string{"open", "/tmp/a.txt"}, nil},
+		{"darwin", o{Target: "https://example.com", App: "Safari"}, []string{"open", "-a", "Safari", "https://example.com"}, nil},
+		{"darwin", o{Target: "/Applications/Foo.app", Args: []string{"-v", "x"}}, []string{"open", "/Applications/Foo.app", "--args", "-v", "x"}, nil},
+		{"darwin", o{Target: "/tmp/a.txt", OpenerArgs: []string{"-g"}}, []string{"open", "-g", "/tmp/a.txt"}, nil},
+		{"darwin", o{Target: "/tmp/a.txt", OpenerArgs: []string{"-a", "Safari"}}, []string{"open", "-a", "Safari", "/tmp/a.txt"}, nil},
+		{"darwin", o{Target: "firefox.desktop"}, nil, actions.ErrUnsupportedOpen},
+		{"linux", o{Target: "/tmp/a.txt"}, []string{"xdg-open", "/tmp/a.txt"}, nil},
+		{"linux", o{Target: "https://example.com/x.desktop"}, []string{"xdg-open", "https://example.com/x.desktop"}, nil},
+		{"linux", o{Target: "firefox.desktop", Args: []string{"-P"}}, []string{"gtk-launch", "firefox.desktop", "-P"}, nil},
+		{"linux", o{Target: "/tmp/a.txt", OpenerArgs: []string{"--foo"}}, nil, actions.ErrUnsupportedOpenerArgs},
+		{"linux", o{Target: "firefox.desktop", OpenerArgs: []string{"--foo"}}, nil, actions.ErrUnsupportedOpenerArgs},
+		{"linux", o{Target: "/tmp/a.txt", App: "gedit", OpenerArgs: []string{"--foo"}}, nil, actions.ErrUnsupportedOpenerArgs},
+		{"linux", o{Target: "/usr/share/applications/foo.desktop"}, []string{"gio", "launch", "/usr/share/applications/foo.desktop"}, nil},
+		{"linux", o{Target: "/tmp/a.txt", App: "gedit.desktop"}, []string{"gtk-launch", "gedit.desktop", "/tmp/a.txt"}, nil},
+		{"linux", o{Target: "/tmp/a.txt", App: "gedit", Args: []string{"-s"}}, []string{"gedit", "-s", "/tmp/a.txt"}, nil},
+		{"windows", o{Target: `C:\a.txt`}, []string{"rundll32", "url.dll,FileProtocolHandler", `C:\a.txt`}, nil},
+		{"windows", o{Target: "https://example.com/?a=1&b=2"}, []string{"rundll32", "url.dll,FileProtocolHandler", "https://example.com/?a=1&b=2"}, nil},
+		{"windows", o{Target: `C:\a.txt`, App: "notepad"}, []string{"notepad", `C:\a.txt`}, nil},
+		{"windows", o{Target: `C:\a.txt`, App: "notepad", Args: []string{"/p"}}, []string{"notepad", `C:\a.txt`, "/p"}, nil},
+		{"windows", o{Target: `C:\foo.exe`, Args: []string{"-v"}}, []string{`C:\foo.exe`, "-v"}, nil},
+		{"windows", o{Target: `C:\a.txt`, OpenerArgs: []string{"/min"}}, nil, actions.ErrUnsupportedOpenerArgs},
+		{"plan9", o{Target: "/tmp/a.txt"}, nil, actions.ErrUnsupportedOpen},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.goos+" "+tc.o.Target, func(t *testing.T) {
+			t.Parallel()
+			got, err := tc.o.Command(tc.goos)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
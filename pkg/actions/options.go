@@ -0,0 +1,109 @@
+package actions
+
+import "time"
+
+// actionOptions holds the named options of an action, as passed via a trailing
+// dictionary argument.
+type actionOptions map[string]interface{}
+
+// parseActionOptions parses an options argument, rejecting unknown options.
+func parseActionOptions(arg interface{}, known ...string) (actionOptions, bool) {
+	opts, ok := arg.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	for name := range opts {
+		isKnown := false
+		for _, k := range known {
+			if name == k {
+				isKnown = true
+				break
+			}
+		}
+		if !isKnown {
+			return nil, false
+		}
+	}
+	return opts, true
+}
+
+// splitActionOptions splits off a trailing options argument from args, if any.
+func splitActionOptions(args []interface{}) ([]interface{}, interface{}) {
+	l := len(args)
+	if l > 0 {
+		if _, ok := args[l-1].(map[string]interface{}); ok {
+			return args[:l-1], args[l-1]
+		}
+	}
+	return args, nil
+}
+
+func (opts actionOptions) bool(name string, def bool) (bool, bool) {
+	v, ok := opts[name]
+	if !ok || v == nil {
+		return def, true
+	}
+	b, ok := v.(bool)
+	return b, ok
+}
+
+func (opts actionOptions) string(name string, def string) (string, bool) {
+	v, ok := opts[name]
+	if !ok || v == nil {
+		return def, true
+	}
+	return stringifySingle(v)
+}
+
+func (opts actionOptions) int(name string, def int) (int, bool) {
+	v, ok := opts[name]
+	if !ok || v == nil {
+		return def, true
+	}
+	i, ok := v.(int)
+	return i, ok
+}
+
+// duration gets a non-negative duration option given in milliseconds.
+func (opts actionOptions) duration(name string, def time.Duration) (time.Duration, bool) {
+	if v, ok := opts[name]; !ok || v == nil {
+		return def, true
+	}
+	ms, ok := opts.int(name, 0)
+	if !ok || ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+func (opts actionOptions) stringList(name string) ([]string, bool) {
+	v, ok := opts[name]
+	if !ok || v == nil {
+		return nil, true
+	}
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	return stringify(l)
+}
+
+func (opts actionOptions) stringMap(name string) (map[string]string, bool) {
+	v, ok := opts[name]
+	if !ok || v == nil {
+		return nil, true
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	strs := make(map[string]string, len(m))
+	for k, v := range m {
+		str, ok := stringifySingle(v)
+		if !ok {
+			return nil, false
+		}
+		strs[k] = str
+	}
+	return strs, true
+}
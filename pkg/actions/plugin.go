@@ -0,0 +1,455 @@
+package actions
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Plugin argument types.
+const (
+	PluginArgString = "string"
+	PluginArgNumber = "number"
+	PluginArgBool   = "bool"
+	PluginArgList   = "list"
+	PluginArgMap    = "map"
+	PluginArgAny    = "any"
+)
+
+// Plugin protocol methods.
+const (
+	pluginMethodDescribe = "describe"
+	pluginMethodInvoke   = "invoke"
+	pluginMethodShutdown = "shutdown"
+)
+
+// defaultPluginTimeout is the timeout of plugin calls without explicit
+// timeout.
+const defaultPluginTimeout = time.Second * 5
+
+// pluginMaxLine is the max length of a plugin message.
+const pluginMaxLine = 1 << 20
+
+// Plugin errors raised by package actions.
+var (
+	ErrPluginExited        = errors.New("plugin exited")
+	ErrPluginTimeout       = errors.New("plugin call timed out")
+	ErrPluginFailed        = errors.New("plugin call failed")
+	ErrInvalidPluginSchema = errors.New("plugin action schema is invalid")
+)
+
+// PluginAction describes an action provided by a plugin.
+type PluginAction struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Args        []PluginArg `json:"args,omitempty"`
+}
+
+// PluginArg describes an argument of a plugin action.
+type PluginArg struct {
+	Name string `json:"name"`
+	// Type is the argument type, i.e. one of the PluginArg* constants;
+	// defaults to PluginArgAny.
+	Type     string `json:"type,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	// Variadic accepts any number of arguments; only valid for the last
+	// argument.
+	Variadic bool `json:"variadic,omitempty"`
+}
+
+// Plugin holds a running plugin, i.e. an external executable providing
+// actions.
+//
+// Plugins communicate via line-delimited JSON-RPC 2.0 messages over their
+// standard input & output. Plugins must implement the following methods:
+//   - describe: lists the provided actions (result: {"actions": [...]}, see
+//     PluginAction).
+//   - invoke: triggers an action (params: {"action": name, "args": [...]}).
+//   - shutdown: prepares the plugin to exit; the plugin exits once its
+//     standard input closed.
+//
+// The standard error of plugins is logged.
+type Plugin struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	opts    PluginOptions
+	actions []PluginAction
+	// registry holds the registry of the plugin actions, if registered.
+	registry *Registry
+
+	calls  map[int64]chan pluginResponse
+	nextID int64
+	closed bool
+	exited chan struct{}
+	mx     sync.Mutex
+	wMx    sync.Mutex
+}
+
+type pluginRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int64       `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type pluginResponse struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type pluginDescription struct {
+	Actions []PluginAction `json:"actions"`
+}
+
+type pluginInvocation struct {
+	Action string        `json:"action"`
+	Args   []interface{} `json:"args"`
+}
+
+var pluginLogger = newTopicLogger("Plugin")
+
+// PluginOptions holds the options of a plugin.
+//
+// Zero values use the respective defaults.
+type PluginOptions struct {
+	// Timeout limits calls to the plugin; defaults to 5 seconds.
+	Timeout time.Duration
+	// InvokeTimeout limits action invocations; defaults to Timeout.
+	InvokeTimeout time.Duration
+}
+
+// StartPlugin starts plugin executable name, and fetches its actions.
+//
+// Calls to the plugin fail after timeout; a zero timeout uses a default
+// timeout.
+func StartPlugin(name string, args []string, timeout time.Duration) (*Plugin, error) {
+	return StartPluginCustom(name, args, PluginOptions{Timeout: timeout})
+}
+
+// StartPluginCustom starts plugin executable name with custom options, and
+// fetches its actions.
+func StartPluginCustom(name string, args []string, opts PluginOptions) (*Plugin, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultPluginTimeout
+	}
+	if opts.InvokeTimeout <= 0 {
+		opts.InvokeTimeout = opts.Timeout
+	}
+	cmd := exec.Command(name, args...)
+	configureChild(cmd)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &Plugin{
+		name:   name,
+		cmd:    cmd,
+		stdin:  stdin,
+		opts:   opts,
+		calls:  make(map[int64]chan pluginResponse),
+		exited: make(chan struct{}),
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p.readResponses(stdout)
+	}()
+	go func() {
+		defer wg.Done()
+		p.readLogs(stderr)
+	}()
+	go func() {
+		wg.Wait()
+		cmd.Wait()
+		close(p.exited)
+	}()
+
+	var desc pluginDescription
+	if err := p.call(context.Background(), pluginMethodDescribe, p.opts.Timeout, nil, &desc); err != nil {
+		p.Shutdown(0)
+		return nil, err
+	}
+	if err := checkPluginActions(desc.Actions); err != nil {
+		p.Shutdown(0)
+		return nil, err
+	}
+	p.actions = desc.Actions
+	return p, nil
+}
+
+func (p *Plugin) readResponses(r io.Reader) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), pluginMaxLine)
+	for s.Scan() {
+		var res pluginResponse
+		if err := json.Unmarshal(s.Bytes(), &res); err != nil {
+			pluginLogger.Printf("Plugin=%s Invalid=%q", p.name, s.Text())
+			continue
+		}
+		p.mx.Lock()
+		ch, ok := p.calls[res.ID]
+		delete(p.calls, res.ID)
+		p.mx.Unlock()
+		if ok {
+			ch <- res
+		}
+	}
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.closed = true
+	for id, ch := range p.calls {
+		close(ch)
+		delete(p.calls, id)
+	}
+}
+
+func (p *Plugin) readLogs(r io.Reader) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), pluginMaxLine)
+	for s.Scan() {
+		pluginLogger.Printf("Plugin=%s Log=%q", p.name, s.Text())
+	}
+}
+
+// call calls a plugin method, and decodes its result into result (if non-nil).
+//
+// The call is abandoned once ctx is done.
+func (p *Plugin) call(
+	ctx context.Context,
+	method string,
+	timeout time.Duration,
+	params interface{},
+	result interface{},
+) error {
+	p.mx.Lock()
+	if p.closed {
+		p.mx.Unlock()
+		return ErrPluginExited
+	}
+	p.nextID++
+	id := p.nextID
+	ch := make(chan pluginResponse, 1)
+	p.calls[id] = ch
+	p.mx.Unlock()
+
+	req, err := json.Marshal(pluginRequest{"2.0", id, method, params})
+	if err == nil {
+		p.wMx.Lock()
+		_, err = p.stdin.Write(append(req, '\n'))
+		p.wMx.Unlock()
+	}
+	if err != nil {
+		p.forget(id)
+		return err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res, ok := <-ch:
+		if !ok {
+			return ErrPluginExited
+		} else if res.Error != nil {
+			return fmt.Errorf("%w: %s (%d)", ErrPluginFailed, res.Error.Message, res.Error.Code)
+		} else if result != nil {
+			return json.Unmarshal(res.Result, result)
+		}
+		return nil
+	case <-timer.C:
+		p.forget(id)
+		return fmt.Errorf("%w: %s", ErrPluginTimeout, method)
+	case <-ctx.Done():
+		p.forget(id)
+		return ctx.Err()
+	}
+}
+
+func (p *Plugin) forget(id int64) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	delete(p.calls, id)
+}
+
+// Name gets the executable name of p.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Actions gets the actions provided by p.
+func (p *Plugin) Actions() []PluginAction {
+	return p.actions
+}
+
+// Invoke triggers action actionName of p, and awaits its completion unless ctx
+// is done or the invoke timeout of p elapsed first.
+func (p *Plugin) Invoke(
+	ctx context.Context,
+	actionName string,
+	args []interface{},
+) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	return p.call(ctx, pluginMethodInvoke, p.opts.InvokeTimeout, pluginInvocation{actionName, args}, nil)
+}
+
+// Shutdown asks p to shut down, and waits for it to exit.
+//
+// The plugin is killed when it did not exit within timeout; a zero timeout
+// uses the call timeout of p.
+func (p *Plugin) Shutdown(timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = p.opts.Timeout
+	}
+	err := p.call(context.Background(), pluginMethodShutdown, p.opts.Timeout, nil, nil)
+	if errors.Is(err, ErrPluginExited) {
+		err = nil
+	}
+	p.stdin.Close()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-p.exited:
+	case <-timer.C:
+		killChild(p.cmd)
+		<-p.exited
+	}
+	return err
+}
+
+func checkPluginActions(as []PluginAction) error {
+	for _, a := range as {
+		if a.Name == "" {
+			return fmt.Errorf("%w: missing action name", ErrInvalidPluginSchema)
+		}
+		optional := false
+		for i, arg := range a.Args {
+			switch arg.Type {
+			case PluginArgString, PluginArgNumber, PluginArgBool,
+				PluginArgList, PluginArgMap, PluginArgAny, "":
+			default:
+				return fmt.Errorf("%w: unknown type \"%s\" of \"%s\"", ErrInvalidPluginSchema, arg.Type, a.Name)
+			}
+			if arg.Variadic && i != len(a.Args)-1 {
+				return fmt.Errorf("%w: non-final variadic argument of \"%s\"", ErrInvalidPluginSchema, a.Name)
+			}
+			if optional && !arg.Optional && !arg.Variadic {
+				return fmt.Errorf("%w: required argument after optional argument of \"%s\"", ErrInvalidPluginSchema, a.Name)
+			}
+			optional = optional || arg.Optional
+		}
+	}
+	return nil
+}
+
+// checkArgs checks whether args match the argument schema of a.
+func (a PluginAction) checkArgs(args []interface{}) bool {
+	i := 0
+	for _, arg := range a.Args {
+		if arg.Variadic {
+			for ; i < len(args); i++ {
+				if !arg.check(args[i]) {
+					return false
+				}
+			}
+			return true
+		}
+		if i >= len(args) {
+			if !arg.Optional {
+				return false
+			}
+			continue
+		}
+		if !arg.check(args[i]) {
+			return false
+		}
+		i++
+	}
+	return i == len(args)
+}
+
+func (arg PluginArg) check(v interface{}) bool {
+	switch arg.Type {
+	case PluginArgString:
+		_, ok := v.(string)
+		return ok
+	case PluginArgNumber:
+		_, ok := numberify(v)
+		return ok
+	case PluginArgBool:
+		_, ok := v.(bool)
+		return ok
+	case PluginArgList:
+		_, ok := v.([]interface{})
+		return ok
+	case PluginArgMap:
+		_, ok := v.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+// RegisterPlugin registers the actions of p in registry r.
+//
+// A nil r uses the default registry (see DefaultRegistry). Plugin actions are
+// created like built-in actions.
+func RegisterPlugin(r *Registry, p *Plugin) error {
+	if r == nil {
+		r = DefaultRegistry()
+	}
+	creators := make(map[string]ActionCreator, len(p.actions))
+	for _, pa := range p.actions {
+		pa := pa
+		creators[pa.Name] = func(args ...interface{}) (Action, error) {
+			if !pa.checkArgs(args) {
+				return nil, ErrInvalidActionArgs
+			}
+			invoke := func(ctx context.Context, _ Trigger) error {
+				return p.Invoke(ctx, pa.Name, args)
+			}
+			return invoke, nil
+		}
+	}
+	if err := r.RegisterAll(creators); err != nil {
+		return err
+	}
+	p.registry = r
+	return nil
+}
+
+// UnregisterPlugin unregisters the actions of p from its registry.
+func UnregisterPlugin(p *Plugin) {
+	if p.registry == nil {
+		return
+	}
+	for _, pa := range p.actions {
+		p.registry.Unregister(pa.Name)
+	}
+	p.registry = nil
+}
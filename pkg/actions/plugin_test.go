@@ -0,0 +1,215 @@
+package actions_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPluginEnv enables the test plugin mode of the test binary, and holds
+// the description of the test plugin.
+const testPluginEnv = "MOUSER_TEST_PLUGIN"
+
+const testPluginActions = `[
+	{"name": "test-plugin:echo", "args": [
+		{"name": "text", "type": "string"},
+		{"name": "count", "type": "number", "optional": true}
+	]},
+	{"name": "test-plugin:fail"},
+	{"name": "test-plugin:slow"},
+	{"name": "test-plugin:any", "args": [{"name": "rest", "variadic": true}]}
+]`
+
+// TestPluginHelperProcess is not a real test; it acts as a plugin when
+// started by StartPlugin tests.
+func TestPluginHelperProcess(t *testing.T) {
+	desc := os.Getenv(testPluginEnv)
+	if desc == "" {
+		return
+	}
+	defer os.Exit(0)
+
+	out := json.NewEncoder(os.Stdout)
+	var outMx sync.Mutex
+	reply := func(id int64, result interface{}, errMsg string) {
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+		if errMsg != "" {
+			res["error"] = map[string]interface{}{"code": 1, "message": errMsg}
+		} else {
+			res["result"] = result
+		}
+		outMx.Lock()
+		defer outMx.Unlock()
+		out.Encode(res)
+	}
+
+	s := bufio.NewScanner(os.Stdin)
+	for s.Scan() {
+		var req struct {
+			ID     int64
+			Method string
+			Params struct {
+				Action string
+				Args   []interface{}
+			}
+		}
+		if err := json.Unmarshal(s.Bytes(), &req); err != nil {
+			fmt.Println("not json")
+			continue
+		}
+		switch req.Method {
+		case "describe":
+			reply(req.ID, map[string]json.RawMessage{"actions": json.RawMessage(desc)}, "")
+		case "invoke":
+			switch req.Params.Action {
+			case "test-plugin:fail":
+				reply(req.ID, nil, "nope")
+			case "test-plugin:slow":
+				// Slow calls must not hold up other calls.
+				go func(id int64) {
+					time.Sleep(time.Second)
+					reply(id, nil, "")
+				}(req.ID)
+			default:
+				args, _ := json.Marshal(req.Params.Args)
+				fmt.Fprintf(os.Stderr, "%s %s\n", req.Params.Action, args)
+				reply(req.ID, nil, "")
+			}
+		case "shutdown":
+			reply(req.ID, nil, "")
+		default:
+			reply(req.ID, nil, "unknown method")
+		}
+	}
+}
+
+func startTestPlugin(t *testing.T, desc string) (*actions.Plugin, error) {
+	t.Setenv(testPluginEnv, desc)
+	return actions.StartPlugin(
+		os.Args[0],
+		[]string{"-test.run=^TestPluginHelperProcess$"},
+		time.Millisecond*500,
+	)
+}
+
+func TestPlugin(t *testing.T) {
+	p, err := startTestPlugin(t, testPluginActions)
+	require.NoError(t, err)
+
+	names := make([]string, len(p.Actions()))
+	for i, pa := range p.Actions() {
+		names[i] = pa.Name
+	}
+	assert.Equal(t, []string{
+		"test-plugin:echo", "test-plugin:fail", "test-plugin:slow", "test-plugin:any",
+	}, names)
+
+	ctx := context.Background()
+	assert.NoError(t, p.Invoke(ctx, "test-plugin:echo", []interface{}{"hi", 2}))
+	assert.ErrorIs(t, p.Invoke(ctx, "test-plugin:fail", nil), actions.ErrPluginFailed)
+	assert.ErrorIs(t, p.Invoke(ctx, "test-plugin:slow", nil), actions.ErrPluginTimeout)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, p.Invoke(cctx, "test-plugin:slow", nil), context.Canceled)
+
+	require.NoError(t, p.Shutdown(time.Second))
+	assert.ErrorIs(t, p.Invoke(ctx, "test-plugin:echo", []interface{}{"hi"}), actions.ErrPluginExited)
+}
+
+func TestPluginInvokeTimeout(t *testing.T) {
+	t.Setenv(testPluginEnv, testPluginActions)
+	p, err := actions.StartPluginCustom(
+		os.Args[0],
+		[]string{"-test.run=^TestPluginHelperProcess$"},
+		actions.PluginOptions{Timeout: time.Millisecond * 500, InvokeTimeout: time.Second * 5},
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { p.Shutdown(time.Second) })
+
+	assert.NoError(t, p.Invoke(context.Background(), "test-plugin:slow", nil))
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	p, err := startTestPlugin(t, testPluginActions)
+	require.NoError(t, err)
+	t.Cleanup(func() { p.Shutdown(time.Second) })
+
+	_, err = actions.New("test-plugin:echo", "hi")
+	assert.ErrorIs(t, err, actions.ErrInvalidActionName)
+
+	require.NoError(t, actions.RegisterPlugin(nil, p))
+	assert.ErrorIs(t, actions.RegisterPlugin(nil, p), actions.ErrDuplicateAction)
+
+	type i = interface{}
+	tests := []struct {
+		name   string
+		args   []i
+		wantOk bool
+	}{
+		{"test-plugin:echo", []i{"hi"}, true},
+		{"test-plugin:echo", []i{"hi", 1.5}, true},
+		{"test-plugin:echo", nil, false},
+		{"test-plugin:echo", []i{1}, false},
+		{"test-plugin:echo", []i{"hi", "x"}, false},
+		{"test-plugin:echo", []i{"hi", 1, 2}, false},
+		{"test-plugin:fail", nil, true},
+		{"test-plugin:fail", []i{1}, false},
+		{"test-plugin:any", nil, true},
+		{"test-plugin:any", []i{1, "a", []i{true}}, true},
+	}
+	for _, tc := range tests {
+		a, err := actions.New(tc.name, tc.args...)
+		if tc.wantOk {
+			assert.NoError(t, err, tc.name, tc.args)
+			assert.NotNil(t, a, tc.name, tc.args)
+		} else {
+			assert.Error(t, err, tc.name, tc.args)
+		}
+	}
+
+	a, err := actions.New("test-plugin:fail")
+	require.NoError(t, err)
+	assert.ErrorIs(t, a(context.Background(), actions.Trigger{}), actions.ErrPluginFailed)
+
+	actions.UnregisterPlugin(p)
+	_, err = actions.New("test-plugin:echo", "hi")
+	assert.ErrorIs(t, err, actions.ErrInvalidActionName)
+}
+
+func TestRegisterPluginBuiltinConflict(t *testing.T) {
+	p, err := startTestPlugin(t, `[{"name": "test-plugin:ok"}, {"name": "misc:none"}]`)
+	require.NoError(t, err)
+	t.Cleanup(func() { p.Shutdown(time.Second) })
+
+	assert.ErrorIs(t, actions.RegisterPlugin(nil, p), actions.ErrDuplicateAction)
+	_, err = actions.New("test-plugin:ok")
+	assert.ErrorIs(t, err, actions.ErrInvalidActionName)
+	actions.UnregisterPlugin(p)
+	_, err = actions.New("misc:none")
+	assert.NoError(t, err)
+}
+
+func TestStartPluginInvalidSchema(t *testing.T) {
+	for _, desc := range []string{
+		`[{"name": ""}]`,
+		`[{"name": "foo", "args": [{"name": "a", "type": "foo"}]}]`,
+		`[{"name": "foo", "args": [{"name": "a", "variadic": true}, {"name": "b"}]}]`,
+		`[{"name": "foo", "args": [{"name": "a", "optional": true}, {"name": "b"}]}]`,
+	} {
+		_, err := startTestPlugin(t, desc)
+		assert.ErrorIs(t, err, actions.ErrInvalidPluginSchema, desc)
+	}
+
+	_, err := actions.StartPlugin("/nonexistent/plugin", nil, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// Pointer position modes.
+const (
+	// PointerAbs positions the pointer at absolute screen coordinates.
+	PointerAbs = "abs"
+	// PointerRel positions the pointer relative to its current position.
+	PointerRel = "rel"
+	// PointerPct positions the pointer at a percentage of the screen size.
+	PointerPct = "pct"
+)
+
+// Mouse button names.
+const (
+	MouseLeft   = "left"
+	MouseRight  = "right"
+	MouseCenter = "center"
+)
+
+const dragStepDelay = time.Millisecond * 20
+
+// Pointer errors raised by package actions.
+var (
+	ErrInvalidPointerMode = errors.New("pointer mode is invalid")
+	ErrInvalidMouseButton = errors.New("mouse button is invalid")
+	ErrInvalidClickCount  = errors.New("click count is invalid")
+)
+
+// NewClick creates an action that clicks a mouse button count times.
+//
+// When drv is nil, the default driver is used.
+func NewClick(drv Driver, button string, count int) (Action, error) {
+	button, err := parseMouseButton(button)
+	if err != nil {
+		return nil, err
+	}
+	if count < 1 {
+		return nil, ErrInvalidClickCount
+	}
+	drv = driverOrDefault(drv)
+	click := func(context.Context, Trigger) error {
+		drv.Click(button, count)
+		return nil
+	}
+	return click, nil
+}
+
+// NewMouseToggle creates an action that presses or releases a mouse button.
+//
+// When drv is nil, the default driver is used.
+func NewMouseToggle(drv Driver, button string, down bool) (Action, error) {
+	button, err := parseMouseButton(button)
+	if err != nil {
+		return nil, err
+	}
+	drv = driverOrDefault(drv)
+	toggle := func(context.Context, Trigger) error {
+		drv.MouseToggle(button, down)
+		return nil
+	}
+	return toggle, nil
+}
+
+// NewMove creates an action that moves the pointer to a position.
+//
+// The position mode is one of PointerAbs, PointerRel or PointerPct. When drv is
+// nil, the default driver is used.
+func NewMove(drv Driver, x, y float64, mode string) (Action, error) {
+	if err := checkPointerPos(x, y, mode); err != nil {
+		return nil, err
+	}
+	drv = driverOrDefault(drv)
+	move := func(context.Context, Trigger) error {
+		drv.MoveTo(getPointerTarget(drv, x, y, mode))
+		return nil
+	}
+	return move, nil
+}
+
+// NewDrag creates an action that drags the pointer from its current position
+// to another position while holding a mouse button.
+//
+// The position mode is one of PointerAbs, PointerRel or PointerPct. When drv is
+// nil, the default driver is used.
+func NewDrag(drv Driver, x, y float64, mode, button string) (Action, error) {
+	if err := checkPointerPos(x, y, mode); err != nil {
+		return nil, err
+	}
+	button, err := parseMouseButton(button)
+	if err != nil {
+		return nil, err
+	}
+	drv = driverOrDefault(drv)
+	drag := func(ctx context.Context, _ Trigger) error {
+		toX, toY := getPointerTarget(drv, x, y, mode)
+		drv.MouseToggle(button, true)
+		// The button is released even when cancelled.
+		defer drv.MouseToggle(button, false)
+		if err := sleepContext(ctx, dragStepDelay); err != nil {
+			return err
+		}
+		drv.MoveTo(toX, toY)
+		return sleepContext(ctx, dragStepDelay)
+	}
+	return drag, nil
+}
+
+// NewScroll creates an action that scrolls by x pixels to the right and y
+// pixels down.
+//
+// When drv is nil, the default driver is used.
+func NewScroll(drv Driver, x, y int) Action {
+	drv = driverOrDefault(drv)
+	return func(context.Context, Trigger) error {
+		drv.Scroll(x, y)
+		return nil
+	}
+}
+
+func driverOrDefault(drv Driver) Driver {
+	if drv == nil {
+		return DefaultDriver()
+	}
+	return drv
+}
+
+func parseMouseButton(button string) (string, error) {
+	switch button {
+	case MouseLeft, "":
+		return MouseLeft, nil
+	case MouseRight:
+		return MouseRight, nil
+	case MouseCenter, "middle":
+		return MouseCenter, nil
+	}
+	return "", ErrInvalidMouseButton
+}
+
+func checkPointerPos(x, y float64, mode string) error {
+	switch mode {
+	case PointerAbs, "":
+		if x < 0 || y < 0 {
+			return ErrInvalidActionArgs
+		}
+	case PointerRel:
+	case PointerPct:
+		if x < 0 || x > 100 || y < 0 || y > 100 {
+			return ErrInvalidActionArgs
+		}
+	default:
+		return ErrInvalidPointerMode
+	}
+	return nil
+}
+
+func getPointerTarget(drv Driver, x, y float64, mode string) (int, int) {
+	switch mode {
+	case PointerRel:
+		curX, curY := drv.PointerPos()
+		return curX + round(x), curY + round(y)
+	case PointerPct:
+		w, h := drv.ScreenSize()
+		// Keep full percentages on-screen.
+		return round(float64(w-1) * x / 100), round(float64(h-1) * y / 100)
+	default:
+		return round(x), round(y)
+	}
+}
+
+func round(f float64) int {
+	return int(math.Round(f))
+}
@@ -0,0 +1,90 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClick(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	click, err := actions.NewClick(drv, "middle", 2)
+	require.NoError(t, err)
+	click(context.Background(), actions.Trigger{})
+	assert.Equal(t, []string{"click center", "click center"}, drv.MouseEvents())
+}
+
+func TestNewMouseToggle(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	down, err := actions.NewMouseToggle(drv, "", true)
+	require.NoError(t, err)
+	up, err := actions.NewMouseToggle(drv, "", false)
+	require.NoError(t, err)
+	down(context.Background(), actions.Trigger{})
+	up(context.Background(), actions.Trigger{})
+	assert.Equal(t, []string{"down left", "up left"}, drv.MouseEvents())
+}
+
+func TestNewScroll(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	scroll := actions.NewScroll(drv, -2, 5)
+	scroll(context.Background(), actions.Trigger{})
+	assert.Equal(t, []string{"scroll -2 5"}, drv.MouseEvents())
+}
+
+func TestNewMove(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		x, y  float64
+		mode  string
+		wantX int
+		wantY int
+	}{
+		{"moves to absolute position", 12, 34, actions.PointerAbs, 12, 34},
+		{"moves to absolute position by default", 12.4, 33.6, "", 12, 34},
+		{"moves relatively", -10, 20, actions.PointerRel, 90, 220},
+		{"moves to screen center", 50, 50, actions.PointerPct, 960, 540},
+		{"moves to screen corner", 100, 0, actions.PointerPct, 1919, 0},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			drv := new(actions.FakeDriver)
+			drv.SetPointerPos(100, 200)
+			drv.SetScreenSize(1920, 1080)
+			move, err := actions.NewMove(drv, tc.x, tc.y, tc.mode)
+			require.NoError(t, err)
+			move(context.Background(), actions.Trigger{})
+			x, y := drv.PointerPos()
+			assert.Equal(t, tc.wantX, x)
+			assert.Equal(t, tc.wantY, y)
+		})
+	}
+}
+
+func TestNewDrag(t *testing.T) {
+	t.Parallel()
+
+	drv := new(actions.FakeDriver)
+	drv.SetPointerPos(100, 200)
+	drag, err := actions.NewDrag(drv, 5, -5, actions.PointerRel, "right")
+	require.NoError(t, err)
+	drag(context.Background(), actions.Trigger{})
+	assert.Equal(t, []string{
+		"down right",
+		"move 105 195",
+		"up right",
+	}, drv.MouseEvents())
+}
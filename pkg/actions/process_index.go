@@ -0,0 +1,248 @@
+package actions
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// Process index settings.
+const (
+	defaultProcessRefreshRate = time.Second * 2
+
+	// procEventsReconcileRate is the full refresh rate of process indexes that
+	// are kept up-to-date via process events, relative to their refresh rate.
+	procEventsReconcileRate = 30
+)
+
+// ProcessLister describes a running processes lister.
+//go:generate mockery --name "ProcessLister"
+type ProcessLister interface {
+	Pids() ([]int32, error)
+	Path(pid int32) string
+}
+
+// ProcessIndex holds a shared index of running processes and their paths.
+//
+// A started index is refreshed periodically, or on process events where
+// supported. An index that was not started is refreshed on every lookup.
+type ProcessIndex struct {
+	lister  ProcessLister
+	paths   map[int32]string
+	young   map[int32]bool
+	fresh   bool
+	running bool
+	stop    chan struct{}
+	done    chan struct{}
+	mx      sync.RWMutex
+	runMx   sync.Mutex
+}
+
+// NewProcessIndex creates a new process index based on a custom lister.
+//
+// When lister is nil, running processes are listed via gopsutil.
+func NewProcessIndex(lister ProcessLister) *ProcessIndex {
+	if lister == nil {
+		lister = gopsutilLister{}
+	}
+	return &ProcessIndex{
+		lister: lister,
+		paths:  make(map[int32]string),
+		young:  make(map[int32]bool),
+	}
+}
+
+var (
+	sharedProcessIndex     *ProcessIndex
+	sharedProcessIndexOnce sync.Once
+)
+
+// DefaultProcessIndex gets the shared default process index.
+func DefaultProcessIndex() *ProcessIndex {
+	sharedProcessIndexOnce.Do(func() {
+		sharedProcessIndex = NewProcessIndex(nil)
+	})
+	return sharedProcessIndex
+}
+
+// Start starts refreshing pi in the background.
+func (pi *ProcessIndex) Start(refreshRate time.Duration) {
+	pi.runMx.Lock()
+	defer pi.runMx.Unlock()
+	if pi.running {
+		return
+	}
+	pi.Refresh()
+	pi.stop = make(chan struct{})
+	pi.done = make(chan struct{})
+	pi.setRunning(true)
+	go pi.watch(refreshRate, pi.stop, pi.done)
+}
+
+// Stop stops refreshing pi in the background.
+func (pi *ProcessIndex) Stop() {
+	pi.runMx.Lock()
+	defer pi.runMx.Unlock()
+	if !pi.running {
+		return
+	}
+	close(pi.stop)
+	<-pi.done
+	pi.setRunning(false)
+}
+
+func (pi *ProcessIndex) setRunning(running bool) {
+	pi.mx.Lock()
+	defer pi.mx.Unlock()
+	pi.running = running
+}
+
+func (pi *ProcessIndex) watch(
+	refreshRate time.Duration,
+	stop <-chan struct{},
+	done chan<- struct{},
+) {
+	defer close(done)
+
+	if refreshRate <= 0 {
+		refreshRate = defaultProcessRefreshRate
+	}
+
+	evs := make(chan procEvent, 64)
+	if watchProcEvents(evs, stop) == nil {
+		refreshRate *= procEventsReconcileRate
+	} else {
+		evs = nil
+	}
+
+	ticker := time.NewTicker(refreshRate)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			pi.Refresh()
+		case ev := <-evs:
+			pi.handleEvent(ev)
+		}
+	}
+}
+
+func (pi *ProcessIndex) handleEvent(ev procEvent) {
+	if ev.exited {
+		pi.mx.Lock()
+		defer pi.mx.Unlock()
+		delete(pi.paths, ev.pid)
+		return
+	}
+	path := pi.lister.Path(ev.pid)
+	pi.mx.Lock()
+	defer pi.mx.Unlock()
+	pi.paths[ev.pid] = path
+}
+
+// Refresh updates pi with the currently running processes.
+func (pi *ProcessIndex) Refresh() error {
+	pids, err := pi.lister.Pids()
+	if err != nil {
+		return err
+	}
+
+	// Paths of known processes are reused. Paths of processes that are new
+	// since the previous refresh are resolved once more, as new processes may
+	// still replace their executable (e.g. via launcher scripts).
+	pi.mx.RLock()
+	paths := make(map[int32]string, len(pids))
+	young := make(map[int32]bool)
+	var resolvePids []int32
+	for _, pid := range pids {
+		if path, ok := pi.paths[pid]; ok && !pi.young[pid] {
+			paths[pid] = path
+		} else {
+			if !ok {
+				young[pid] = true
+			}
+			resolvePids = append(resolvePids, pid)
+		}
+	}
+	pi.mx.RUnlock()
+
+	for _, pid := range resolvePids {
+		paths[pid] = pi.lister.Path(pid)
+	}
+
+	pi.mx.Lock()
+	defer pi.mx.Unlock()
+	pi.paths = paths
+	pi.young = young
+	pi.fresh = true
+	return nil
+}
+
+func (pi *ProcessIndex) ensureFresh() {
+	pi.mx.RLock()
+	fresh := pi.running && pi.fresh
+	pi.mx.RUnlock()
+	if !fresh {
+		pi.Refresh()
+	}
+}
+
+// Path gets the path of a process by pid.
+func (pi *ProcessIndex) Path(pid int32) string {
+	pi.mx.RLock()
+	path, ok := pi.paths[pid]
+	pi.mx.RUnlock()
+	if ok {
+		return path
+	}
+
+	// Only started indexes cache lookups of unknown processes, as unstarted
+	// indexes never drop exited processes until their next refresh.
+	path = pi.lister.Path(pid)
+	if path != "" {
+		pi.mx.Lock()
+		defer pi.mx.Unlock()
+		if pi.running {
+			pi.paths[pid] = path
+			pi.young[pid] = true
+		}
+	}
+	return path
+}
+
+// Find looks up a running process by matching app path.
+func (pi *ProcessIndex) Find(match AppMatcher) (pid int32, ok bool) {
+	pi.ensureFresh()
+	pi.mx.RLock()
+	defer pi.mx.RUnlock()
+	for pid, path := range pi.paths {
+		if match(path) {
+			return pid, true
+		}
+	}
+	return 0, false
+}
+
+// procEvent holds a process lifecycle event.
+type procEvent struct {
+	pid    int32
+	exited bool
+}
+
+// gopsutilLister implements a running processes lister via gopsutil.
+type gopsutilLister struct{}
+
+func (gopsutilLister) Pids() ([]int32, error) {
+	return process.Pids()
+}
+
+func (gopsutilLister) Path(pid int32) string {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return ""
+	}
+	return getProcessPath(p)
+}
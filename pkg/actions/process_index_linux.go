@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"encoding/binary"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Linux process connector constants (see linux/connector.h & linux/cn_proc.h).
+const (
+	cnIdxProc          = 0x1
+	cnValProc          = 0x1
+	procCnMcastListen  = 0x1
+	procEventFork      = 0x1
+	procEventExec      = 0x2
+	procEventExit      = 0x80000000
+	nlMsgHdrLen        = 16
+	cnMsgLen           = 20
+	procEventHdrLen    = 16
+	procEventsRecvWait = time.Second
+)
+
+// watchProcEvents subscribes to process events via the Linux process
+// connector.
+//
+// Subscribing requires elevated privileges (CAP_NET_ADMIN); an error is
+// returned when events are unavailable.
+func watchProcEvents(evs chan<- procEvent, stop <-chan struct{}) error {
+	fd, err := syscall.Socket(
+		syscall.AF_NETLINK,
+		syscall.SOCK_DGRAM,
+		syscall.NETLINK_CONNECTOR,
+	)
+	if err != nil {
+		return err
+	}
+	sa := &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: cnIdxProc,
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		syscall.Close(fd)
+		return err
+	}
+	if err := syscall.Sendto(fd, newProcCnListenMsg(), 0, sa); err != nil {
+		syscall.Close(fd)
+		return err
+	}
+	tv := syscall.NsecToTimeval(int64(procEventsRecvWait))
+	if err := syscall.SetsockoptTimeval(
+		fd,
+		syscall.SOL_SOCKET,
+		syscall.SO_RCVTIMEO,
+		&tv,
+	); err != nil {
+		syscall.Close(fd)
+		return err
+	}
+	go readProcEvents(fd, evs, stop)
+	return nil
+}
+
+func newProcCnListenMsg() []byte {
+	const l = nlMsgHdrLen + cnMsgLen + 4
+	msg := make([]byte, l)
+	ne := binary.NativeEndian
+	// struct nlmsghdr
+	ne.PutUint32(msg[0:], l)
+	ne.PutUint16(msg[4:], syscall.NLMSG_DONE)
+	// struct cn_msg
+	ne.PutUint32(msg[16:], cnIdxProc)
+	ne.PutUint32(msg[20:], cnValProc)
+	ne.PutUint16(msg[32:], 4)
+	// enum proc_cn_mcast_op
+	ne.PutUint32(msg[36:], procCnMcastListen)
+	return msg
+}
+
+func readProcEvents(fd int, evs chan<- procEvent, stop <-chan struct{}) {
+	defer syscall.Close(fd)
+	buf := make([]byte, os.Getpagesize())
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			if err == syscall.EAGAIN || err == syscall.EINTR {
+				continue
+			}
+			return
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, msg := range msgs {
+			if ev, ok := parseProcEvent(msg.Data); ok {
+				select {
+				case evs <- ev:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}
+}
+
+func parseProcEvent(data []byte) (procEvent, bool) {
+	if len(data) < cnMsgLen+procEventHdrLen+16 {
+		return procEvent{}, false
+	}
+	ne := binary.NativeEndian
+	ev := data[cnMsgLen:]
+	what := ne.Uint32(ev[0:])
+	body := ev[procEventHdrLen:]
+	switch what {
+	case procEventFork:
+		// Ignore new threads of existing processes.
+		childPid, childTgid := ne.Uint32(body[8:]), ne.Uint32(body[12:])
+		if childPid != childTgid {
+			return procEvent{}, false
+		}
+		return procEvent{pid: int32(childTgid)}, true
+	case procEventExec, procEventExit:
+		pid, tgid := ne.Uint32(body[0:]), ne.Uint32(body[4:])
+		if pid != tgid {
+			return procEvent{}, false
+		}
+		return procEvent{pid: int32(tgid), exited: what == procEventExit}, true
+	}
+	return procEvent{}, false
+}
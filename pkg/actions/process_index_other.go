@@ -0,0 +1,9 @@
+//go:build !linux
+
+package actions
+
+import "errors"
+
+func watchProcEvents(evs chan<- procEvent, stop <-chan struct{}) error {
+	return errors.New("process events are not supported on this platform")
+}
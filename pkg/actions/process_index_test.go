@@ -0,0 +1,214 @@
+package actions_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/echocrow/Mouser/pkg/actions/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockProcessLister(
+	procs map[int32]string,
+) (*mocks.ProcessLister, func(map[int32]string)) {
+	var mx sync.Mutex
+	l := new(mocks.ProcessLister)
+	l.On("Pids").Return(func() []int32 {
+		mx.Lock()
+		defer mx.Unlock()
+		pids := make([]int32, 0, len(procs))
+		for pid := range procs {
+			pids = append(pids, pid)
+		}
+		return pids
+	}, nil)
+	l.On("Path", mock.AnythingOfType("int32")).Return(func(pid int32) string {
+		mx.Lock()
+		defer mx.Unlock()
+		return procs[pid]
+	})
+	setProcs := func(p map[int32]string) {
+		mx.Lock()
+		defer mx.Unlock()
+		procs = p
+	}
+	return l, setProcs
+}
+
+func matchPath(want string) actions.AppMatcher {
+	return func(path string) bool { return path == want }
+}
+
+func TestProcessIndexFind(t *testing.T) {
+	t.Parallel()
+
+	l, setProcs := newMockProcessLister(map[int32]string{
+		1: "/foo",
+		2: "/bar",
+	})
+	pi := actions.NewProcessIndex(l)
+
+	pid, ok := pi.Find(matchPath("/foo"))
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), pid)
+
+	_, ok = pi.Find(matchPath("/baz"))
+	assert.False(t, ok)
+
+	setProcs(map[int32]string{2: "/bar", 3: "/baz"})
+
+	_, ok = pi.Find(matchPath("/foo"))
+	assert.False(t, ok, "want unstarted index to refresh on lookup")
+	pid, ok = pi.Find(matchPath("/baz"))
+	assert.True(t, ok, "want unstarted index to refresh on lookup")
+	assert.Equal(t, int32(3), pid)
+}
+
+func TestProcessIndexRefresh(t *testing.T) {
+	t.Parallel()
+
+	l, setProcs := newMockProcessLister(map[int32]string{
+		1: "/foo",
+		2: "/bar",
+	})
+	pi := actions.NewProcessIndex(l)
+
+	require.NoError(t, pi.Refresh())
+	l.AssertNumberOfCalls(t, "Path", 2)
+
+	setProcs(map[int32]string{1: "/foo", 2: "/bar-exec", 3: "/baz"})
+	require.NoError(t, pi.Refresh())
+	l.AssertNumberOfCalls(t, "Path", 5)
+
+	assert.Equal(t, "/foo", pi.Path(1))
+	assert.Equal(t, "/bar-exec", pi.Path(2))
+	assert.Equal(t, "/baz", pi.Path(3))
+	l.AssertNumberOfCalls(t, "Path", 5)
+
+	require.NoError(t, pi.Refresh())
+	l.AssertNumberOfCalls(t, "Path", 6)
+
+	require.NoError(t, pi.Refresh())
+	l.AssertNumberOfCalls(t, "Path", 6)
+
+	setProcs(map[int32]string{1: "/foo"})
+	require.NoError(t, pi.Refresh())
+	assert.Equal(t, "", pi.Path(2))
+	l.AssertNumberOfCalls(t, "Path", 7)
+}
+
+func TestProcessIndexPathCache(t *testing.T) {
+	t.Parallel()
+
+	l, setProcs := newMockProcessLister(map[int32]string{1: "/foo"})
+	pi := actions.NewProcessIndex(l)
+
+	setProcs(map[int32]string{1: "/foo", 2: "/bar"})
+	assert.Equal(t, "/bar", pi.Path(2))
+	setProcs(map[int32]string{1: "/foo", 2: "/bar-exec"})
+	assert.Equal(t, "/bar-exec", pi.Path(2), "want unstarted index not to cache")
+
+	pi.Start(time.Hour)
+	defer pi.Stop()
+
+	setProcs(map[int32]string{1: "/foo", 2: "/bar-exec", 3: "/baz"})
+	assert.Equal(t, "/baz", pi.Path(3))
+	setProcs(map[int32]string{1: "/foo", 2: "/bar-exec", 3: "/baz-exec"})
+	assert.Equal(t, "/baz", pi.Path(3), "want started index to cache")
+}
+
+func TestProcessIndexStart(t *testing.T) {
+	t.Parallel()
+
+	l, setProcs := newMockProcessLister(map[int32]string{1: "/foo"})
+	pi := actions.NewProcessIndex(l)
+
+	pi.Start(time.Millisecond)
+	defer pi.Stop()
+
+	_, ok := pi.Find(matchPath("/foo"))
+	assert.True(t, ok)
+
+	setProcs(map[int32]string{2: "/bar"})
+	assert.Eventually(t, func() bool {
+		_, ok := pi.Find(matchPath("/bar"))
+		return ok
+	}, time.Second, time.Millisecond)
+
+	pi.Stop()
+	pi.Stop()
+}
+
+func TestNewRequireAppProcessIndex(t *testing.T) {
+	t.Parallel()
+
+	const (
+		app      = "app"
+		fallback = "fallback"
+	)
+
+	calls := make(chan string, 2)
+
+	l, setProcs := newMockProcessLister(map[int32]string{1: "/foo/bar"})
+	pi := actions.NewProcessIndex(l)
+
+	reqAppAction, err := actions.NewRequireApp(
+		"/foo",
+		newTestAction(func() { calls <- app }),
+		newTestAction(func() { calls <- fallback }),
+		pi,
+	)
+	require.NoError(t, err)
+
+	assertActionCalls(t, reqAppAction, true, app, calls)
+	setProcs(map[int32]string{2: "/bar"})
+	assertActionCalls(t, reqAppAction, true, fallback, calls)
+	setProcs(map[int32]string{2: "/bar", 3: "/foo/baz"})
+	assertActionCalls(t, reqAppAction, true, app, calls)
+}
+
+func TestNewAppBranchConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mx    sync.Mutex
+		calls = make(map[string]int)
+	)
+	call := func(name string) act {
+		return newTestAction(func() {
+			mx.Lock()
+			defer mx.Unlock()
+			calls[name]++
+		})
+	}
+
+	fg := new(actions.FakeForeground)
+	branchAction, err := actions.NewAppBranchCustom(
+		map[string]act{"/foo": call("foo")},
+		nil,
+		call("fallback"),
+		fg,
+		'/',
+	)
+	require.NoError(t, err)
+
+	const n = 200
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fg.Set(actions.ForegroundApp{Path: fmt.Sprintf("/foo/%d", i%3)})
+			branchAction(context.Background(), actions.Trigger{})
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"foo": n}, calls)
+}
@@ -0,0 +1,187 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Rate limit errors raised by package actions.
+var (
+	ErrInvalidCooldown = errors.New("cooldown must be positive")
+	ErrNoEdges         = errors.New("rate limit requires a leading or trailing edge")
+)
+
+func checkRateLimit(cooldown time.Duration, leading, trailing bool) error {
+	if cooldown <= 0 {
+		return ErrInvalidCooldown
+	} else if !leading && !trailing {
+		return ErrNoEdges
+	}
+	return nil
+}
+
+// NewThrottle creates an action triggering a at most once per cooldown.
+//
+// With leading, a is triggered immediately when not cooling down. With
+// trailing, triggers during a cooldown are deferred until the cooldown ended;
+// deferred triggers report failures to the failure handler (see
+// SetFailureHandler), and are skipped once their context is done.
+func NewThrottle(
+	a Action,
+	cooldown time.Duration,
+	leading, trailing bool,
+) (Action, error) {
+	return NewThrottleCustom(a, cooldown, leading, trailing, SystemClock{})
+}
+
+// NewThrottleCustom creates a throttled action based on a custom clock.
+func NewThrottleCustom(
+	a Action,
+	cooldown time.Duration,
+	leading, trailing bool,
+	clock Clock,
+) (Action, error) {
+	if err := checkRateLimit(cooldown, leading, trailing); err != nil {
+		return nil, err
+	}
+	var (
+		mx      sync.Mutex
+		cooling bool
+		pending *deferredRun
+	)
+	var endCooldown func()
+	endCooldown = func() {
+		mx.Lock()
+		if pending == nil {
+			cooling = false
+			mx.Unlock()
+			return
+		}
+		run := pending
+		pending = nil
+		clock.AfterFunc(cooldown, endCooldown)
+		mx.Unlock()
+		run.do("throttle", a)
+	}
+	throttled := func(ctx context.Context, ev Trigger) error {
+		mx.Lock()
+		if cooling {
+			if trailing {
+				pending = &deferredRun{ctx, ev}
+			}
+			mx.Unlock()
+			return nil
+		}
+		cooling = true
+		clock.AfterFunc(cooldown, endCooldown)
+		if !leading {
+			pending = &deferredRun{ctx, ev}
+			mx.Unlock()
+			return nil
+		}
+		mx.Unlock()
+		return runAction(ctx, a, ev)
+	}
+	return throttled, nil
+}
+
+// NewDebounce creates an action triggering a only once triggers paused for a
+// cooldown.
+//
+// With leading, a is triggered immediately by the first trigger after a
+// pause. With trailing, a is triggered once the cooldown after the last
+// trigger elapsed (unless that trigger was already handled as leading edge);
+// trailing triggers report failures to the failure handler (see
+// SetFailureHandler), and are skipped once their context is done.
+func NewDebounce(
+	a Action,
+	cooldown time.Duration,
+	leading, trailing bool,
+) (Action, error) {
+	return NewDebounceCustom(a, cooldown, leading, trailing, SystemClock{})
+}
+
+// NewDebounceCustom creates a debounced action based on a custom clock.
+func NewDebounceCustom(
+	a Action,
+	cooldown time.Duration,
+	leading, trailing bool,
+	clock Clock,
+) (Action, error) {
+	if err := checkRateLimit(cooldown, leading, trailing); err != nil {
+		return nil, err
+	}
+	var (
+		mx      sync.Mutex
+		timer   Timer
+		gen     int
+		waiting bool
+		pending *deferredRun
+	)
+	settle := func(g int) func() {
+		return func() {
+			mx.Lock()
+			if g != gen {
+				// Superseded by a later trigger.
+				mx.Unlock()
+				return
+			}
+			waiting = false
+			run := pending
+			pending = nil
+			mx.Unlock()
+			if run != nil {
+				run.do("debounce", a)
+			}
+		}
+	}
+	debounced := func(ctx context.Context, ev Trigger) error {
+		mx.Lock()
+		runNow := leading && !waiting
+		if !runNow {
+			pending = nil
+			if trailing {
+				pending = &deferredRun{ctx, ev}
+			}
+		}
+		waiting = true
+		if timer != nil {
+			timer.Stop()
+		}
+		gen++
+		timer = clock.AfterFunc(cooldown, settle(gen))
+		mx.Unlock()
+		if runNow {
+			return runAction(ctx, a, ev)
+		}
+		return nil
+	}
+	return debounced, nil
+}
+
+// deferredRun holds a trigger of an action deferred until a cooldown ended.
+type deferredRun struct {
+	ctx context.Context
+	ev  Trigger
+}
+
+// do triggers a with the deferred trigger, unless its context is done, and
+// reports failures of a as failures of action name.
+func (r *deferredRun) do(name string, a Action) {
+	if r.ctx.Err() != nil {
+		return
+	}
+	if err := runAction(r.ctx, a, r.ev); err != nil && r.ctx.Err() == nil {
+		reportFailure(name, err)
+	}
+}
+
+// runAction triggers a, unless a is nil.
+func runAction(ctx context.Context, a Action, ev Trigger) error {
+	if a == nil {
+		return nil
+	}
+	return a(ctx, ev)
+}
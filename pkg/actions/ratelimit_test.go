@@ -0,0 +1,146 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rateLimitCreator func(
+	a actions.Action,
+	cooldown time.Duration,
+	leading, trailing bool,
+	clock actions.Clock,
+) (actions.Action, error)
+
+// runRateLimited triggers a rate-limited action at the given millisecond
+// offsets, and gets the offsets at which the wrapped action ran.
+func runRateLimited(
+	t *testing.T,
+	create rateLimitCreator,
+	leading, trailing bool,
+	triggers []int,
+) []int {
+	clock := actions.NewFakeClock(time.Unix(0, 0))
+	start := clock.Now()
+	var got []int
+	a := newTestAction(func() { got = append(got, int(clock.Now().Sub(start)/time.Millisecond)) })
+
+	rl, err := create(a, time.Millisecond*100, leading, trailing, clock)
+	require.NoError(t, err)
+	for _, at := range triggers {
+		clock.Advance(start.Add(time.Duration(at) * time.Millisecond).Sub(clock.Now()))
+		rl(context.Background(), actions.Trigger{})
+	}
+	clock.Advance(time.Second)
+	return got
+}
+
+func TestNewThrottle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		leading  bool
+		trailing bool
+		triggers []int
+		want     []int
+	}{
+		{"leading single", true, false, []int{0}, []int{0}},
+		{"leading", true, false, []int{0, 10, 50, 99, 100, 150, 250}, []int{0, 100, 250}},
+		{"trailing single", false, true, []int{0}, []int{100}},
+		{"trailing", false, true, []int{0, 10, 50, 150, 250}, []int{100, 200, 300}},
+		{"both single", true, true, []int{0}, []int{0}},
+		{"both", true, true, []int{0, 10, 50, 150, 350}, []int{0, 100, 200, 350}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := runRateLimited(t, actions.NewThrottleCustom, tc.leading, tc.trailing, tc.triggers)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewDebounce(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		leading  bool
+		trailing bool
+		triggers []int
+		want     []int
+	}{
+		{"trailing single", false, true, []int{0}, []int{100}},
+		{"trailing", false, true, []int{0, 50, 120, 300}, []int{220, 400}},
+		{"leading single", true, false, []int{0}, []int{0}},
+		{"leading", true, false, []int{0, 50, 120, 300}, []int{0, 300}},
+		{"both single", true, true, []int{0}, []int{0}},
+		{"both", true, true, []int{0, 50, 120, 300}, []int{0, 220, 300}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := runRateLimited(t, actions.NewDebounceCustom, tc.leading, tc.trailing, tc.triggers)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRateLimitErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, create := range []rateLimitCreator{
+		actions.NewThrottleCustom,
+		actions.NewDebounceCustom,
+	} {
+		clock := actions.NewFakeClock(time.Unix(0, 0))
+		_, err := create(nil, 0, true, false, clock)
+		assert.ErrorIs(t, err, actions.ErrInvalidCooldown)
+		_, err = create(nil, time.Second, false, false, clock)
+		assert.ErrorIs(t, err, actions.ErrNoEdges)
+		a, err := create(nil, time.Second, true, true, clock)
+		require.NoError(t, err)
+		a(context.Background(), actions.Trigger{})
+		clock.Advance(time.Second)
+	}
+}
+
+func TestNewThrottleSystemClock(t *testing.T) {
+	t.Parallel()
+
+	count := make(chan struct{}, 10)
+	a, err := actions.NewThrottle(newTestAction(func() { count <- struct{}{} }), time.Millisecond*50, true, true)
+	require.NoError(t, err)
+	a(context.Background(), actions.Trigger{})
+	a(context.Background(), actions.Trigger{})
+	a(context.Background(), actions.Trigger{})
+	time.Sleep(time.Millisecond * 200)
+	assert.Len(t, count, 2)
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	t.Parallel()
+
+	for _, create := range []rateLimitCreator{
+		actions.NewThrottleCustom,
+		actions.NewDebounceCustom,
+	} {
+		clock := actions.NewFakeClock(time.Unix(0, 0))
+		calls := 0
+		a, err := create(newTestAction(func() { calls++ }), time.Second, false, true, clock)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(t, a(ctx, actions.Trigger{}))
+		cancel()
+		clock.Advance(time.Second)
+		assert.Zero(t, calls, "want no deferred run after cancellation")
+	}
+}
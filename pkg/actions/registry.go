@@ -0,0 +1,164 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// actionNamespaceSep separates the namespace and the name of actions, e.g.
+// "io:tap".
+const actionNamespaceSep = ":"
+
+// Registry holds a set of named actions.
+//
+// Action names are namespaced, i.e. of the form "namespace:name".
+type Registry struct {
+	creators map[string]ActionCreator
+	mx       sync.RWMutex
+}
+
+// NewRegistry creates a new, empty action registry.
+func NewRegistry() *Registry {
+	return &Registry{creators: make(map[string]ActionCreator)}
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// DefaultRegistry gets the shared registry holding the built-in actions.
+func DefaultRegistry() *Registry {
+	defaultRegistryOnce.Do(func() {
+		defaultRegistry = NewRegistry()
+		for name, a := range basicActions {
+			defaultRegistry.creators[name] = newBasicActionCreator(a)
+		}
+		for name, ac := range actionCreators {
+			defaultRegistry.creators[name] = ac
+		}
+	})
+	return defaultRegistry
+}
+
+// Register registers a custom action in the default registry (see
+// DefaultRegistry).
+func Register(name string, creator ActionCreator) error {
+	return DefaultRegistry().Register(name, creator)
+}
+
+// newBasicActionCreator creates an action creator of an action without
+// arguments.
+func newBasicActionCreator(a Action) ActionCreator {
+	return func(args ...interface{}) (Action, error) {
+		if len(args) != 0 {
+			return nil, ErrInvalidActionArgs
+		}
+		return a, nil
+	}
+}
+
+// checkActionName checks whether name is a valid namespaced action name.
+func checkActionName(name string) error {
+	ns, n, ok := strings.Cut(name, actionNamespaceSep)
+	if !ok || ns == "" || n == "" {
+		return fmt.Errorf("%w: \"%s\" is not of the form \"namespace:name\"", ErrInvalidActionName, name)
+	}
+	return nil
+}
+
+// Clone creates a copy of r.
+func (r *Registry) Clone() *Registry {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	c := NewRegistry()
+	for name, ac := range r.creators {
+		c.creators[name] = ac
+	}
+	return c
+}
+
+// Register registers action name created by creator.
+func (r *Registry) Register(name string, creator ActionCreator) error {
+	return r.RegisterAll(map[string]ActionCreator{name: creator})
+}
+
+// RegisterBasic registers action name without arguments.
+func (r *Registry) RegisterBasic(name string, a Action) error {
+	return r.Register(name, newBasicActionCreator(a))
+}
+
+// RegisterNamespace registers a set of actions within namespace ns, e.g.
+// action "deploy" within namespace "team" as "team:deploy".
+//
+// Either all or none of the actions are registered.
+func (r *Registry) RegisterNamespace(ns string, creators map[string]ActionCreator) error {
+	nsCreators := make(map[string]ActionCreator, len(creators))
+	for name, creator := range creators {
+		nsCreators[ns+actionNamespaceSep+name] = creator
+	}
+	return r.RegisterAll(nsCreators)
+}
+
+// RegisterAll registers a set of actions by name.
+//
+// Either all or none of the actions are registered.
+func (r *Registry) RegisterAll(creators map[string]ActionCreator) error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	for name, creator := range creators {
+		if err := checkActionName(name); err != nil {
+			return err
+		} else if creator == nil {
+			return fmt.Errorf("%w: \"%s\"", ErrInvalidActionCreator, name)
+		} else if _, ok := r.creators[name]; ok {
+			return fmt.Errorf("%w: \"%s\"", ErrDuplicateAction, name)
+		}
+	}
+	for name, creator := range creators {
+		r.creators[name] = creator
+	}
+	return nil
+}
+
+// Unregister unregisters actions by name.
+func (r *Registry) Unregister(names ...string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	for _, name := range names {
+		delete(r.creators, name)
+	}
+}
+
+// Has checks whether action name is registered.
+func (r *Registry) Has(name string) bool {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	_, ok := r.creators[name]
+	return ok
+}
+
+// Names gets the sorted names of all registered actions.
+func (r *Registry) Names() []string {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	names := make([]string, 0, len(r.creators))
+	for name := range r.creators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a registered action.
+func (r *Registry) New(actionName string, args ...interface{}) (Action, error) {
+	r.mx.RLock()
+	creator, ok := r.creators[actionName]
+	r.mx.RUnlock()
+	if !ok {
+		return nil, ErrInvalidActionName
+	}
+	return creator(args...)
+}
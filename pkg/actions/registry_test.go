@@ -0,0 +1,149 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCreator(calls *int) actions.ActionCreator {
+	return func(args ...interface{}) (actions.Action, error) {
+		if len(args) > 1 {
+			return nil, actions.ErrInvalidActionArgs
+		}
+		return newTestAction(func() { *calls++ }), nil
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		wantErr error
+	}{
+		{"team:deploy", nil},
+		{"team:deploy:prod", nil},
+		{"deploy", actions.ErrInvalidActionName},
+		{":deploy", actions.ErrInvalidActionName},
+		{"team:", actions.ErrInvalidActionName},
+		{"", actions.ErrInvalidActionName},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := actions.NewRegistry()
+			calls := 0
+			err := r.Register(tc.name, newTestCreator(&calls))
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.False(t, r.Has(tc.name))
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, r.Has(tc.name))
+
+			a, err := r.New(tc.name)
+			require.NoError(t, err)
+			a(context.Background(), actions.Trigger{})
+			assert.Equal(t, 1, calls)
+			_, err = r.New(tc.name, 1, 2)
+			assert.ErrorIs(t, err, actions.ErrInvalidActionArgs)
+
+			err = r.Register(tc.name, newTestCreator(&calls))
+			assert.ErrorIs(t, err, actions.ErrDuplicateAction)
+		})
+	}
+}
+
+func TestRegistryRegisterNilCreator(t *testing.T) {
+	t.Parallel()
+	r := actions.NewRegistry()
+	err := r.Register("team:deploy", nil)
+	assert.ErrorIs(t, err, actions.ErrInvalidActionCreator)
+	assert.False(t, r.Has("team:deploy"))
+}
+
+func TestRegistryRegisterBasic(t *testing.T) {
+	t.Parallel()
+	r := actions.NewRegistry()
+	calls := 0
+	require.NoError(t, r.RegisterBasic("team:ping", newTestAction(func() { calls++ })))
+
+	a, err := r.New("team:ping")
+	require.NoError(t, err)
+	a(context.Background(), actions.Trigger{})
+	assert.Equal(t, 1, calls)
+	_, err = r.New("team:ping", "foo")
+	assert.ErrorIs(t, err, actions.ErrInvalidActionArgs)
+}
+
+func TestRegistryRegisterNamespace(t *testing.T) {
+	t.Parallel()
+	r := actions.NewRegistry()
+	calls := 0
+	require.NoError(t, r.RegisterNamespace("team", map[string]actions.ActionCreator{
+		"deploy":   newTestCreator(&calls),
+		"rollback": newTestCreator(&calls),
+	}))
+	assert.Equal(t, []string{"team:deploy", "team:rollback"}, r.Names())
+
+	// Conflicting sets are rejected as a whole.
+	err := r.RegisterNamespace("team", map[string]actions.ActionCreator{
+		"status": newTestCreator(&calls),
+		"deploy": newTestCreator(&calls),
+	})
+	assert.ErrorIs(t, err, actions.ErrDuplicateAction)
+	assert.False(t, r.Has("team:status"))
+
+	err = r.RegisterNamespace("", map[string]actions.ActionCreator{
+		"status": newTestCreator(&calls),
+	})
+	assert.ErrorIs(t, err, actions.ErrInvalidActionName)
+	assert.Equal(t, []string{"team:deploy", "team:rollback"}, r.Names())
+}
+
+func TestRegistryUnregister(t *testing.T) {
+	t.Parallel()
+	r := actions.NewRegistry()
+	calls := 0
+	require.NoError(t, r.Register("team:deploy", newTestCreator(&calls)))
+	require.NoError(t, r.Register("team:status", newTestCreator(&calls)))
+
+	r.Unregister("team:deploy", "team:unknown")
+	assert.Equal(t, []string{"team:status"}, r.Names())
+	_, err := r.New("team:deploy")
+	assert.ErrorIs(t, err, actions.ErrInvalidActionName)
+	assert.NoError(t, r.Register("team:deploy", newTestCreator(&calls)))
+}
+
+func TestRegistryClone(t *testing.T) {
+	t.Parallel()
+	r := actions.DefaultRegistry().Clone()
+	calls := 0
+	require.NoError(t, r.Register("test-clone:deploy", newTestCreator(&calls)))
+	assert.True(t, r.Has("misc:none"))
+	assert.False(t, actions.DefaultRegistry().Has("test-clone:deploy"))
+
+	r.Unregister("misc:none")
+	assert.True(t, actions.DefaultRegistry().Has("misc:none"))
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	require.NoError(t, actions.Register("test-register:deploy", newTestCreator(&calls)))
+	t.Cleanup(func() { actions.DefaultRegistry().Unregister("test-register:deploy") })
+
+	a, err := actions.New("test-register:deploy")
+	require.NoError(t, err)
+	a(context.Background(), actions.Trigger{})
+	assert.Equal(t, 1, calls)
+
+	err = actions.Register("misc:none", newTestCreator(&calls))
+	assert.ErrorIs(t, err, actions.ErrDuplicateAction)
+}
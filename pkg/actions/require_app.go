@@ -1,9 +1,9 @@
 package actions
 
 import (
+	"context"
 	"os"
 	"strings"
-	"sync"
 
 	"github.com/shirou/gopsutil/v4/process"
 )
@@ -14,16 +14,28 @@ const (
 )
 
 // NewRequireApp creates an app-dependent action branch.
+//
+// The app is matched by app pattern (see NewAppMatcher). Running processes are
+// looked up via pi, or via DefaultProcessIndex when pi is nil.
 func NewRequireApp(
 	app string,
 	do Action,
 	fallback Action,
-) Action {
-	return NewRequireAppCustom(
-		newAppRunningChecker(app).run,
+	pi *ProcessIndex,
+) (Action, error) {
+	match, err := NewAppMatcher(app, os.PathSeparator)
+	if err != nil {
+		return nil, err
+	}
+	if pi == nil {
+		pi = DefaultProcessIndex()
+	}
+	a := NewRequireAppCustom(
+		newAppRunningChecker(match, pi).run,
 		do,
 		fallback,
 	)
+	return a, nil
 }
 
 // NewRequireAppCustom creates an app-based actions branch based on a custom
@@ -33,72 +45,31 @@ func NewRequireAppCustom(
 	do Action,
 	fallback Action,
 ) Action {
-	return func() {
+	return func(ctx context.Context, ev Trigger) error {
 		action := do
 		if !checkAppRunning() {
 			action = fallback
 		}
-		if action != nil {
-			action()
-		}
+		return runAction(ctx, action, ev)
 	}
 }
 
-var osPathSep = string(os.PathSeparator)
-
 type appRunningChecker struct {
-	pid int32
-	app string
-	mx  sync.RWMutex
-}
-
-func newAppRunningChecker(app string) *appRunningChecker {
-	arc := new(appRunningChecker)
-	arc.app = app
-	return arc
+	match AppMatcher
+	pi    *ProcessIndex
 }
 
-func (arc *appRunningChecker) checkCache() bool {
-	arc.mx.RLock()
-	defer arc.mx.RUnlock()
-	if arc.pid != 0 {
-		path := getPidPath(arc.pid)
-		if matchAppPathPrefix(path, arc.app, osPathSep) {
-			return true
-		} else {
-			arc.pid = 0
-		}
-	}
-	return false
+func newAppRunningChecker(match AppMatcher, pi *ProcessIndex) *appRunningChecker {
+	return &appRunningChecker{match, pi}
 }
 
 func (arc *appRunningChecker) run() bool {
-	if arc.checkCache() {
-		return true
-	}
-
-	// Scan processes.
-	ps, _ := process.Processes()
-	// We presume on average user-related apps are closer to the end than to the
-	// start of the list of processes, thus we loop through in reverse.
-	for i := len(ps) - 1; i >= 0; i-- {
-		p := ps[i]
-		pid := p.Pid
-		path := getProcessPath(p)
-		if matchAppPathPrefix(path, arc.app, osPathSep) {
-			arc.mx.Lock()
-			defer arc.mx.Unlock()
-			arc.pid = pid
-			return true
-		}
-	}
-
-	return false
+	_, ok := arc.pi.Find(arc.match)
+	return ok
 }
 
 func getPidPath(pid int32) string {
-	p, _ := process.NewProcess(pid)
-	return getProcessPath(p)
+	return DefaultProcessIndex().Path(pid)
 }
 
 func getProcessPath(p *process.Process) string {
@@ -120,7 +91,3 @@ func getProcessPath(p *process.Process) string {
 
 	return path
 }
-
-func matchAppPathPrefix(path, name, pathSep string) bool {
-	return name != "" && (path == name || strings.HasPrefix(path, name+pathSep))
-}
@@ -16,8 +16,8 @@ func TestNewRequireAppCustom(t *testing.T) {
 	calls := make(chan string, 2)
 
 	var (
-		appAct      act = func() { calls <- app }
-		fallbackAct     = func() { calls <- fallback }
+		appAct      act = newTestAction(func() { calls <- app })
+		fallbackAct     = newTestAction(func() { calls <- fallback })
 	)
 
 	isAppRunning := false
@@ -0,0 +1,73 @@
+package actions
+
+import "errors"
+
+// Scancode errors raised by package actions.
+var (
+	ErrUnsupportedScancode = errors.New("sending scancodes is not supported on this platform")
+)
+
+// ScancodeDriver describes a driver able to press & release physical keys by
+// their (set 1) scancode, regardless of the active keyboard layout.
+type ScancodeDriver interface {
+	ScancodeToggle(code uint16, down bool) error
+}
+
+// physKeyDriver implements a system input/output driver sending physical keys
+// (i.e. "phys:<us-key>" or "sc:<scancode>") as scancodes.
+//
+// When the underlying driver does not support scancodes, physical keys are
+// passed on by their US layout name instead.
+type physKeyDriver struct {
+	Driver
+}
+
+func (d physKeyDriver) scancodeDriver(key string) (ScancodeDriver, uint16, bool) {
+	code, ok := physKeyScancode(key)
+	if !ok {
+		return nil, 0, false
+	}
+	drv := d.Driver
+	if _, ok := drv.(sharedBaseDriver); ok {
+		drv = getDriver()
+	}
+	sd, ok := drv.(ScancodeDriver)
+	return sd, code, ok
+}
+
+// KeyTap triggers a short key press & release while holding modifiers.
+func (d physKeyDriver) KeyTap(key string, modifiers ...string) {
+	if sd, code, ok := d.scancodeDriver(key); ok {
+		for _, m := range modifiers {
+			d.KeyToggle(m, true)
+		}
+		err := sd.ScancodeToggle(code, true)
+		if err == nil {
+			sd.ScancodeToggle(code, false)
+		}
+		for i := len(modifiers) - 1; i >= 0; i-- {
+			d.KeyToggle(modifiers[i], false)
+		}
+		if err == nil {
+			return
+		}
+	}
+	key, _ = resolvePhysKey(key)
+	d.Driver.KeyTap(key, modifiers...)
+}
+
+// KeyToggle presses or releases a key.
+func (d physKeyDriver) KeyToggle(key string, down bool) {
+	if sd, code, ok := d.scancodeDriver(key); ok {
+		if sd.ScancodeToggle(code, down) == nil {
+			return
+		}
+	}
+	key, _ = resolvePhysKey(key)
+	d.Driver.KeyToggle(key, down)
+}
+
+// ScancodeToggle presses or releases a physical key by scancode.
+func (RobotGoDriver) ScancodeToggle(code uint16, down bool) error {
+	return sendScancode(code, down)
+}
@@ -0,0 +1,49 @@
+//go:build darwin && cgo
+
+package actions
+
+// #cgo darwin LDFLAGS: -framework Carbon
+// #include <Carbon/Carbon.h>
+import "C"
+
+// macKeyCodes maps (set 1) scancodes to macOS virtual keycodes.
+var macKeyCodes = map[uint16]C.CGKeyCode{
+	0x01: C.kVK_Escape,
+	0x02: C.kVK_ANSI_1, 0x03: C.kVK_ANSI_2, 0x04: C.kVK_ANSI_3,
+	0x05: C.kVK_ANSI_4, 0x06: C.kVK_ANSI_5, 0x07: C.kVK_ANSI_6,
+	0x08: C.kVK_ANSI_7, 0x09: C.kVK_ANSI_8, 0x0A: C.kVK_ANSI_9,
+	0x0B: C.kVK_ANSI_0, 0x0C: C.kVK_ANSI_Minus, 0x0D: C.kVK_ANSI_Equal,
+	0x0E: C.kVK_Delete, 0x0F: C.kVK_Tab,
+	0x10: C.kVK_ANSI_Q, 0x11: C.kVK_ANSI_W, 0x12: C.kVK_ANSI_E,
+	0x13: C.kVK_ANSI_R, 0x14: C.kVK_ANSI_T, 0x15: C.kVK_ANSI_Y,
+	0x16: C.kVK_ANSI_U, 0x17: C.kVK_ANSI_I, 0x18: C.kVK_ANSI_O,
+	0x19: C.kVK_ANSI_P, 0x1A: C.kVK_ANSI_LeftBracket,
+	0x1B: C.kVK_ANSI_RightBracket, 0x1C: C.kVK_Return, 0x1D: C.kVK_Control,
+	0x1E: C.kVK_ANSI_A, 0x1F: C.kVK_ANSI_S, 0x20: C.kVK_ANSI_D,
+	0x21: C.kVK_ANSI_F, 0x22: C.kVK_ANSI_G, 0x23: C.kVK_ANSI_H,
+	0x24: C.kVK_ANSI_J, 0x25: C.kVK_ANSI_K, 0x26: C.kVK_ANSI_L,
+	0x27: C.kVK_ANSI_Semicolon, 0x28: C.kVK_ANSI_Quote,
+	0x29: C.kVK_ANSI_Grave, 0x2A: C.kVK_Shift, 0x2B: C.kVK_ANSI_Backslash,
+	0x2C: C.kVK_ANSI_Z, 0x2D: C.kVK_ANSI_X, 0x2E: C.kVK_ANSI_C,
+	0x2F: C.kVK_ANSI_V, 0x30: C.kVK_ANSI_B, 0x31: C.kVK_ANSI_N,
+	0x32: C.kVK_ANSI_M, 0x33: C.kVK_ANSI_Comma, 0x34: C.kVK_ANSI_Period,
+	0x35: C.kVK_ANSI_Slash, 0x36: C.kVK_RightShift,
+	0x38: C.kVK_Option, 0x39: C.kVK_Space, 0x3A: C.kVK_CapsLock,
+	0x3B: C.kVK_F1, 0x3C: C.kVK_F2, 0x3D: C.kVK_F3, 0x3E: C.kVK_F4,
+	0x3F: C.kVK_F5, 0x40: C.kVK_F6, 0x41: C.kVK_F7, 0x42: C.kVK_F8,
+	0x43: C.kVK_F9, 0x44: C.kVK_F10, 0x57: C.kVK_F11, 0x58: C.kVK_F12,
+}
+
+func sendScancode(code uint16, down bool) error {
+	keyCode, ok := macKeyCodes[code]
+	if !ok {
+		return ErrUnsupportedScancode
+	}
+	ev := C.CGEventCreateKeyboardEvent(0, keyCode, C.bool(down))
+	if ev == 0 {
+		return ErrUnsupportedScancode
+	}
+	defer C.CFRelease(C.CFTypeRef(ev))
+	C.CGEventPost(C.kCGHIDEventTap, ev)
+	return nil
+}
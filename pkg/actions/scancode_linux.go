@@ -0,0 +1,49 @@
+package actions
+
+import (
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+	"github.com/jezek/xgb/xtest"
+)
+
+// evdevKeycodeOffset is the offset of X11 keycodes of evdev-based X servers
+// from Linux key codes, which match set 1 scancodes for the main keys.
+const evdevKeycodeOffset = 8
+
+// xtestConn holds the X11 connection of scancodes sent via XTEST; it is
+// opened once and shared for the lifetime of the process.
+var xtestConn struct {
+	conn *xgb.Conn
+	root xproto.Window
+	err  error
+	once sync.Once
+}
+
+func sendScancode(code uint16, down bool) error {
+	xc := &xtestConn
+	xc.once.Do(func() {
+		conn, err := xgb.NewConn()
+		if err != nil {
+			xc.err = err
+			return
+		}
+		if err := xtest.Init(conn); err != nil {
+			conn.Close()
+			xc.err = err
+			return
+		}
+		xc.conn = conn
+		xc.root = xproto.Setup(conn).DefaultScreen(conn).Root
+	})
+	if xc.err != nil {
+		return xc.err
+	}
+	evType := byte(xproto.KeyPress)
+	if !down {
+		evType = xproto.KeyRelease
+	}
+	keycode := byte(code + evdevKeycodeOffset)
+	return xtest.FakeInputChecked(xc.conn, evType, keycode, 0, xc.root, 0, 0, 0).Check()
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !windows && !(darwin && cgo)
+
+package actions
+
+func sendScancode(code uint16, down bool) error {
+	return ErrUnsupportedScancode
+}
@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procSendInput = syscall.NewLazyDLL("user32.dll").NewProc("SendInput")
+
+// SendInput constants.
+const (
+	inputKeyboard     = 1
+	keyeventfKeyUp    = 0x0002
+	keyeventfScancode = 0x0008
+)
+
+// keyboardInput mirrors the Win32 INPUT structure of keyboard events.
+type keyboardInput struct {
+	inputType uint32
+	ki        struct {
+		vk        uint16
+		scan      uint16
+		flags     uint32
+		time      uint32
+		extraInfo uintptr
+	}
+	// padding pads the structure to the size of its largest union member.
+	padding [8]byte
+}
+
+func sendScancode(code uint16, down bool) error {
+	in := keyboardInput{inputType: inputKeyboard}
+	in.ki.scan = code
+	in.ki.flags = keyeventfScancode
+	if !down {
+		in.ki.flags |= keyeventfKeyUp
+	}
+	n, _, err := procSendInput.Call(1, uintptr(unsafe.Pointer(&in)), unsafe.Sizeof(in))
+	if n != 1 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package actions
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+)
+
+// defaultShell gets the default shell name and its command flag.
+func defaultShell() (name, flag string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", "/C"
+	}
+	return "sh", "-c"
+}
+
+// newShellCmd creates a command running script via the default shell, which
+// is killed once ctx is done.
+func newShellCmd(ctx context.Context, script string) *exec.Cmd {
+	name, flag := defaultShell()
+	return newKillableCmd(ctx, name, flag, script)
+}
+
+// newKillableCmd creates a command which is killed along with its child
+// processes once ctx is done.
+func newKillableCmd(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	configureChild(cmd)
+	cmd.Cancel = func() error { return killChild(cmd) }
+	cmd.WaitDelay = cmdWaitDelay
+	return cmd
+}
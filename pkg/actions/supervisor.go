@@ -0,0 +1,278 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Process instance policies.
+const (
+	// PolicyParallel starts a new process regardless of running ones.
+	PolicyParallel = "parallel"
+	// PolicySingleInstance skips starting a process while one is running.
+	PolicySingleInstance = "single-instance"
+	// PolicyRestart kills a running process before starting a new one.
+	PolicyRestart = "restart"
+)
+
+// Supervisor shutdown modes.
+const (
+	// ShutdownDetach leaves running processes running.
+	ShutdownDetach = "detach"
+	// ShutdownWait waits for running processes to exit, and kills them after a
+	// timeout.
+	ShutdownWait = "wait"
+	// ShutdownKill kills running processes.
+	ShutdownKill = "kill"
+)
+
+// shutdownKillGrace is the time processes get to exit after being asked to
+// terminate on shutdown, before being killed forcibly.
+const shutdownKillGrace = 2 * time.Second
+
+// Supervisor errors raised by package actions.
+var (
+	ErrInvalidPolicy   = errors.New("process instance policy is invalid")
+	ErrInvalidShutdown = errors.New("supervisor shutdown mode is invalid")
+	// ErrProcessRunning is returned when skipping a process of a single-instance
+	// policy.
+	ErrProcessRunning = errors.New("process is already running")
+	// ErrProcessRestarted is returned for processes killed to restart them.
+	ErrProcessRestarted = errors.New("process was restarted")
+)
+
+// Supervisor tracks & reaps spawned child processes.
+type Supervisor struct {
+	children map[*child]struct{}
+	byKey    map[string]*child
+	mx       sync.Mutex
+}
+
+type child struct {
+	key       string
+	cmd       *exec.Cmd
+	done      chan struct{}
+	restarted bool
+}
+
+// NewSupervisor creates a new process supervisor.
+func NewSupervisor() *Supervisor {
+	return &Supervisor{
+		children: make(map[*child]struct{}),
+		byKey:    make(map[string]*child),
+	}
+}
+
+var (
+	sharedSupervisor     *Supervisor
+	sharedSupervisorOnce sync.Once
+)
+
+// DefaultSupervisor gets the shared default process supervisor.
+func DefaultSupervisor() *Supervisor {
+	sharedSupervisorOnce.Do(func() {
+		sharedSupervisor = NewSupervisor()
+	})
+	return sharedSupervisor
+}
+
+var supervisorKeys uint64
+
+// newSupervisorKey creates a new unique process key.
+func newSupervisorKey() string {
+	return "#" + strconv.FormatUint(atomic.AddUint64(&supervisorKeys, 1), 10)
+}
+
+// supervisorKeyCtxKey is the context key of process keys set via
+// withSupervisorKey.
+type supervisorKeyCtxKey struct{}
+
+// withSupervisorKey gets a context replacing the default process key of
+// commands run with it by key.
+//
+// Actions that are rebuilt on each trigger (e.g. templated actions) thus keep
+// their process key, and remain subject to their instance policy.
+func withSupervisorKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, supervisorKeyCtxKey{}, key)
+}
+
+// supervisorKeyFrom gets the process key set via withSupervisorKey, if any.
+func supervisorKeyFrom(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(supervisorKeyCtxKey{}).(string)
+	return key, ok
+}
+
+// CheckPolicy checks whether policy is a valid process instance policy.
+func CheckPolicy(policy string) error {
+	switch policy {
+	case "", PolicyParallel, PolicySingleInstance, PolicyRestart:
+		return nil
+	}
+	return ErrInvalidPolicy
+}
+
+// CheckShutdownMode checks whether mode is a valid supervisor shutdown mode.
+func CheckShutdownMode(mode string) error {
+	switch mode {
+	case "", ShutdownDetach, ShutdownWait, ShutdownKill:
+		return nil
+	}
+	return ErrInvalidShutdown
+}
+
+// Run starts cmd and waits for it to exit.
+//
+// Processes sharing a key are subject to the instance policy.
+func (s *Supervisor) Run(key, policy string, cmd *exec.Cmd) error {
+	c, err := s.start(key, policy, cmd)
+	if err != nil {
+		return err
+	}
+	return s.wait(c)
+}
+
+// Start starts cmd and reaps it in the background.
+//
+// Processes sharing a key are subject to the instance policy.
+func (s *Supervisor) Start(key, policy string, cmd *exec.Cmd) error {
+	c, err := s.start(key, policy, cmd)
+	if err != nil {
+		return err
+	}
+	go s.wait(c)
+	return nil
+}
+
+func (s *Supervisor) start(key, policy string, cmd *exec.Cmd) (*child, error) {
+	if err := CheckPolicy(policy); err != nil {
+		return nil, err
+	}
+	if policy == PolicyParallel {
+		key = ""
+	}
+	configureChild(cmd)
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for prev := s.byKey[key]; key != "" && prev != nil; prev = s.byKey[key] {
+		if policy == PolicySingleInstance {
+			return nil, ErrProcessRunning
+		}
+		prev.restarted = true
+		killChild(prev.cmd)
+		s.mx.Unlock()
+		<-prev.done
+		s.mx.Lock()
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	c := &child{key: key, cmd: cmd, done: make(chan struct{})}
+	s.children[c] = struct{}{}
+	if key != "" {
+		s.byKey[key] = c
+	}
+	return c, nil
+}
+
+func (s *Supervisor) wait(c *child) error {
+	err := c.cmd.Wait()
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	delete(s.children, c)
+	if c.key != "" && s.byKey[c.key] == c {
+		delete(s.byKey, c.key)
+	}
+	close(c.done)
+	if c.restarted {
+		return ErrProcessRestarted
+	}
+	return err
+}
+
+// Running gets the number of running processes.
+func (s *Supervisor) Running() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.children)
+}
+
+// Shutdown detaches, waits for or kills all running processes.
+//
+// When waiting, processes still running after timeout are killed. Killed
+// processes are asked to terminate first (SIGTERM), and only killed forcibly
+// when still running after a grace period.
+func (s *Supervisor) Shutdown(mode string, timeout time.Duration) error {
+	if err := CheckShutdownMode(mode); err != nil {
+		return err
+	}
+
+	s.mx.Lock()
+	children := make([]*child, 0, len(s.children))
+	for c := range s.children {
+		children = append(children, c)
+	}
+	s.mx.Unlock()
+
+	switch mode {
+	case ShutdownDetach, "":
+		return nil
+	case ShutdownWait:
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired := false
+		var running []*child
+		for _, c := range children {
+			if !expired {
+				select {
+				case <-c.done:
+					continue
+				case <-timer.C:
+					expired = true
+				}
+			}
+			select {
+			case <-c.done:
+			default:
+				running = append(running, c)
+			}
+		}
+		stopChildren(running)
+	case ShutdownKill:
+		stopChildren(children)
+	}
+	return nil
+}
+
+// stopChildren asks children to terminate, and kills those still running after
+// a grace period.
+func stopChildren(children []*child) {
+	for _, c := range children {
+		terminateChild(c.cmd)
+	}
+	timer := time.NewTimer(shutdownKillGrace)
+	defer timer.Stop()
+	expired := false
+	for _, c := range children {
+		if !expired {
+			select {
+			case <-c.done:
+				continue
+			case <-timer.C:
+				expired = true
+			}
+		}
+		select {
+		case <-c.done:
+		default:
+			killChild(c.cmd)
+			<-c.done
+		}
+	}
+}
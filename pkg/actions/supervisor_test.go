@@ -0,0 +1,150 @@
+package actions_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisorRun(t *testing.T) {
+	t.Parallel()
+
+	s := actions.NewSupervisor()
+	assert.NoError(t, s.Run("", "", exec.Command("true")))
+	assert.Error(t, s.Run("", "", exec.Command("false")))
+	assert.Error(t, s.Run("", "", exec.Command("mouser-missing-cmd")))
+	assert.ErrorIs(t, s.Run("", "foo", exec.Command("true")), actions.ErrInvalidPolicy)
+	assert.Equal(t, 0, s.Running())
+}
+
+func TestSupervisorStartReaps(t *testing.T) {
+	t.Parallel()
+
+	s := actions.NewSupervisor()
+	require.NoError(t, s.Start("", "", exec.Command("sleep", "0.05")))
+	assert.Equal(t, 1, s.Running())
+	assert.Eventually(t, func() bool {
+		return s.Running() == 0
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestSupervisorPolicies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy      string
+		wantErr     error
+		wantRunning int
+	}{
+		{actions.PolicyParallel, nil, 2},
+		{actions.PolicySingleInstance, actions.ErrProcessRunning, 1},
+		{actions.PolicyRestart, actions.ErrProcessRestarted, 1},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.policy, func(t *testing.T) {
+			t.Parallel()
+			s := actions.NewSupervisor()
+			t.Cleanup(func() { s.Shutdown(actions.ShutdownKill, 0) })
+
+			firstErr := make(chan error, 1)
+			go func() {
+				firstErr <- s.Run("foo", tc.policy, exec.Command("sleep", "5"))
+			}()
+			require.Eventually(t, func() bool {
+				return s.Running() == 1
+			}, time.Second, time.Millisecond)
+
+			err := s.Start("foo", tc.policy, exec.Command("sleep", "5"))
+			if tc.policy == actions.PolicySingleInstance {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantRunning, s.Running())
+
+			if tc.policy == actions.PolicyRestart {
+				assert.ErrorIs(t, <-firstErr, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSupervisorShutdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		mode        string
+		script      string
+		timeout     time.Duration
+		wantRunning int
+		wantMinDur  time.Duration
+	}{
+		{"detaches", actions.ShutdownDetach, "sleep 5", 0, 1, 0},
+		{"kills process group", actions.ShutdownKill, "sleep 5; true", time.Minute, 0, 0},
+		{"waits", actions.ShutdownWait, "sleep 0.1", time.Minute, 0, time.Millisecond * 100},
+		{"kills after timeout", actions.ShutdownWait, "sleep 5; true", time.Millisecond * 50, 0, time.Millisecond * 50},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			s := actions.NewSupervisor()
+			t.Cleanup(func() { s.Shutdown(actions.ShutdownKill, 0) })
+
+			require.NoError(t, s.Start("", "", exec.Command("sh", "-c", tc.script)))
+
+			start := time.Now()
+			require.NoError(t, s.Shutdown(tc.mode, tc.timeout))
+			d := time.Since(start)
+			assert.GreaterOrEqual(t, d, tc.wantMinDur)
+			assert.Less(t, d, time.Second*4)
+			assert.Equal(t, tc.wantRunning, s.Running())
+		})
+	}
+
+	assert.ErrorIs(t, actions.NewSupervisor().Shutdown("foo", 0), actions.ErrInvalidShutdown)
+}
+
+func TestSupervisorShutdownTerminates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		trap       string
+		want       string
+		wantMinDur time.Duration
+	}{
+		{"terminates first", `echo bye > "$1"; exit 0`, "bye\n", 0},
+		{"kills after grace period", ``, "ready\n", time.Second * 2},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			out := filepath.Join(t.TempDir(), "out")
+			s := actions.NewSupervisor()
+			script := `trap '` + tc.trap + `' TERM; echo ready > "$1"; sleep 5 & wait`
+			require.NoError(t, s.Start("", "", exec.Command("sh", "-c", script, "sh", out)))
+			require.Eventually(t, func() bool {
+				got, _ := os.ReadFile(out)
+				return string(got) == "ready\n"
+			}, time.Second, time.Millisecond*10)
+
+			start := time.Now()
+			require.NoError(t, s.Shutdown(actions.ShutdownKill, 0))
+			d := time.Since(start)
+			assert.GreaterOrEqual(t, d, tc.wantMinDur)
+			assert.Less(t, d, time.Second*4)
+			got, _ := os.ReadFile(out)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
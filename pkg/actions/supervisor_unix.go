@@ -0,0 +1,33 @@
+//go:build !windows
+
+package actions
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureChild starts cmd in its own process group, so that it can be
+// killed along with its descendants.
+func configureChild(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killChild kills the process group of a started cmd.
+func killChild(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// terminateChild asks the process group of a started cmd to terminate.
+func terminateChild(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
@@ -0,0 +1,22 @@
+package actions
+
+import (
+	"os/exec"
+)
+
+// configureChild prepares cmd for supervision.
+func configureChild(cmd *exec.Cmd) {}
+
+// killChild kills a started cmd.
+func killChild(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+// terminateChild kills a started cmd, as Windows processes cannot be asked to
+// terminate via signals.
+func terminateChild(cmd *exec.Cmd) error {
+	return killChild(cmd)
+}
@@ -0,0 +1,509 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Template delimiters.
+const (
+	templateOpen  = "{{"
+	templateClose = "}}"
+)
+
+// defaultTemplateDateLayout is the date layout of date placeholders without
+// explicit layout.
+const defaultTemplateDateLayout = "2006-01-02"
+
+// templateCmdTimeout limits how long command placeholders may run.
+const templateCmdTimeout = time.Second * 5
+
+// Template errors raised by package actions.
+var (
+	ErrInvalidTemplate     = errors.New("template is invalid")
+	ErrUnknownPlaceholder  = errors.New("template placeholder is unknown")
+	ErrTemplateValueFailed = errors.New("template value lookup failed")
+)
+
+// templatedArgsCheckers check the parsed templated arguments of actions, e.g.
+// to reject unsafe placeholders.
+var templatedArgsCheckers = map[string]func(args []interface{}) error{
+	"os:cmd": checkCmdTemplates,
+}
+
+// TemplateContext provides the values of template placeholders.
+type TemplateContext struct {
+	Foreground ForegroundProvider
+	Driver     Driver
+	Now        func() time.Time
+	Getenv     func(string) string
+}
+
+// Template holds a parsed string template.
+//
+// Templates contain placeholders such as "{{env.HOME}}" or
+// "{{date "2006-01-02"}}".
+type Template struct {
+	src   string
+	parts []templatePart
+}
+
+type templatePart struct {
+	text string
+	expr *templateExpr
+}
+
+type templateExpr struct {
+	name   string
+	field  string
+	arg    string
+	hasArg bool
+	isText bool
+}
+
+// IsTemplate checks whether str contains template placeholders.
+func IsTemplate(str string) bool {
+	return strings.Contains(str, templateOpen)
+}
+
+// ParseTemplate parses a string template.
+func ParseTemplate(str string) (*Template, error) {
+	t := &Template{src: str}
+	for str != "" {
+		i := strings.Index(str, templateOpen)
+		if i < 0 {
+			t.parts = append(t.parts, templatePart{text: str})
+			break
+		}
+		if i > 0 {
+			t.parts = append(t.parts, templatePart{text: str[:i]})
+		}
+		str = str[i+len(templateOpen):]
+		j := indexTemplateClose(str)
+		if j < 0 {
+			return nil, fmt.Errorf("%w: unclosed placeholder", ErrInvalidTemplate)
+		}
+		expr, err := parseTemplateExpr(str[:j])
+		if err != nil {
+			return nil, err
+		}
+		t.parts = append(t.parts, templatePart{expr: expr})
+		str = str[j+len(templateClose):]
+	}
+	return t, nil
+}
+
+// indexTemplateClose finds the closing delimiter of a placeholder, skipping
+// quoted strings.
+func indexTemplateClose(str string) int {
+	var quote byte
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case strings.HasPrefix(str[i:], templateClose):
+			return i
+		}
+	}
+	return -1
+}
+
+func parseTemplateExpr(str string) (*templateExpr, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return nil, fmt.Errorf("%w: empty placeholder", ErrInvalidTemplate)
+	}
+
+	// Literal text, e.g. {{"{{"}}.
+	if str[0] == '"' || str[0] == '`' {
+		text, err := strconv.Unquote(str)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTemplate, err)
+		}
+		return &templateExpr{arg: text, isText: true}, nil
+	}
+
+	ref, argStr, hasArg := strings.Cut(str, " ")
+	expr := &templateExpr{}
+	expr.name, expr.field, _ = strings.Cut(ref, ".")
+	if hasArg {
+		arg, err := strconv.Unquote(strings.TrimSpace(argStr))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTemplate, err)
+		}
+		expr.arg = arg
+		expr.hasArg = true
+	}
+
+	fields, wantArg, ok := expr.signature()
+	if !ok || !isTemplateField(fields, expr.field) {
+		return nil, fmt.Errorf("%w: \"%s\"", ErrUnknownPlaceholder, ref)
+	}
+	if expr.hasArg && !wantArg {
+		return nil, fmt.Errorf("%w: unexpected argument for \"%s\"", ErrInvalidTemplate, ref)
+	}
+	if expr.name == "cmd" && !expr.hasArg {
+		return nil, fmt.Errorf("%w: missing argument for \"%s\"", ErrInvalidTemplate, ref)
+	}
+	return expr, nil
+}
+
+// signature gets the supported fields and whether an argument is supported by
+// expr. Empty (non-nil) fields support any field name.
+func (expr *templateExpr) signature() (fields []string, arg bool, ok bool) {
+	switch expr.name {
+	case "env":
+		return []string{}, false, true
+	case "app":
+		return []string{"path", "name"}, false, true
+	case "window":
+		return []string{"title"}, false, true
+	case "pointer":
+		return []string{"x", "y"}, false, true
+	case "clipboard":
+		return nil, false, true
+	case "date":
+		return nil, true, true
+	case "cmd":
+		return nil, true, true
+	}
+	return nil, false, false
+}
+
+func isTemplateField(fields []string, field string) bool {
+	if fields == nil {
+		return field == ""
+	} else if len(fields) == 0 {
+		return field != ""
+	}
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// String gets the source of t.
+func (t *Template) String() string {
+	return t.src
+}
+
+// IsNumeric checks whether t consists of a single numeric placeholder (e.g.
+// "{{pointer.x}}").
+func (t *Template) IsNumeric() bool {
+	return len(t.parts) == 1 && t.parts[0].expr != nil &&
+		t.parts[0].expr.name == "pointer"
+}
+
+// Expand expands t with the values provided by tc.
+//
+// Command placeholders are killed once ctx is done.
+func (t *Template) Expand(ctx context.Context, tc TemplateContext) (string, error) {
+	var sb strings.Builder
+	te := templateExpansion{ctx: tc, runCtx: ctx}
+	for _, p := range t.parts {
+		if p.expr == nil {
+			sb.WriteString(p.text)
+			continue
+		}
+		val, err := te.eval(p.expr)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(val)
+	}
+	return sb.String(), nil
+}
+
+// templateExpansion holds lazily resolved values of a single expansion.
+type templateExpansion struct {
+	ctx    TemplateContext
+	runCtx context.Context
+	app    *ForegroundApp
+	ptrX   int
+	ptrY   int
+	ptrOk  bool
+}
+
+func (te *templateExpansion) foreground() ForegroundApp {
+	if te.app == nil {
+		var app ForegroundApp
+		if te.ctx.Foreground != nil {
+			app = foregroundApp(te.runCtx, te.ctx.Foreground)
+		}
+		te.app = &app
+	}
+	return *te.app
+}
+
+func (te *templateExpansion) pointer() (x, y int) {
+	if !te.ptrOk && te.ctx.Driver != nil {
+		te.ptrX, te.ptrY = te.ctx.Driver.PointerPos()
+		te.ptrOk = true
+	}
+	return te.ptrX, te.ptrY
+}
+
+func (te *templateExpansion) eval(expr *templateExpr) (string, error) {
+	switch {
+	case expr.isText:
+		return expr.arg, nil
+	case expr.name == "env":
+		if te.ctx.Getenv == nil {
+			return "", nil
+		}
+		return te.ctx.Getenv(expr.field), nil
+	case expr.name == "app" && expr.field == "path":
+		return te.foreground().Path, nil
+	case expr.name == "app" && expr.field == "name":
+		path := te.foreground().Path
+		if path == "" {
+			return "", nil
+		}
+		return trimExt(filepath.Base(path)), nil
+	case expr.name == "window":
+		return te.foreground().Title, nil
+	case expr.name == "pointer" && expr.field == "x":
+		x, _ := te.pointer()
+		return strconv.Itoa(x), nil
+	case expr.name == "pointer" && expr.field == "y":
+		_, y := te.pointer()
+		return strconv.Itoa(y), nil
+	case expr.name == "clipboard":
+		if te.ctx.Driver == nil {
+			return "", nil
+		}
+		text, err := te.ctx.Driver.ReadClipboard()
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrTemplateValueFailed, err)
+		}
+		return text, nil
+	case expr.name == "date":
+		layout := defaultTemplateDateLayout
+		if expr.hasArg {
+			layout = expr.arg
+		}
+		now := time.Now
+		if te.ctx.Now != nil {
+			now = te.ctx.Now
+		}
+		return now().Format(layout), nil
+	case expr.name == "cmd":
+		ctx, cancel := context.WithTimeout(te.runCtx, templateCmdTimeout)
+		defer cancel()
+		out, err := newShellCmd(ctx, expr.arg).Output()
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrTemplateValueFailed, err)
+		}
+		return strings.TrimRight(string(out), "\r\n"), nil
+	}
+	return "", ErrUnknownPlaceholder
+}
+
+// NewTemplated creates an action of the default registry (see
+// DefaultRegistry) with templated string arguments.
+func NewTemplated(
+	actionName string,
+	ctx TemplateContext,
+	args ...interface{},
+) (Action, error) {
+	return DefaultRegistry().NewTemplated(actionName, ctx, args...)
+}
+
+// NewTemplated creates a registered action with templated string arguments.
+//
+// Template placeholders (see ParseTemplate) are expanded each time the action
+// is triggered, including within list & dictionary arguments. Arguments that
+// consist of a single numeric placeholder (see Template.IsNumeric) expand to
+// numbers.
+//
+// The action is built once per distinct set of expanded arguments, and reused
+// while recently triggered (see templatedActionsCap), so that stateful actions
+// keep their state. Arguments are validated upfront, with placeholders
+// standing in for their values; arguments with numeric placeholders are only
+// validated once expanded.
+func (r *Registry) NewTemplated(
+	actionName string,
+	ctx TemplateContext,
+	args ...interface{},
+) (Action, error) {
+	tmplArgs := make([]interface{}, len(args))
+	isTemplated := false
+	for i, arg := range args {
+		tmplArg, ok, err := parseTemplatedArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		tmplArgs[i] = tmplArg
+		isTemplated = isTemplated || ok
+	}
+	if !isTemplated {
+		return r.New(actionName, args...)
+	}
+	if check, ok := templatedArgsCheckers[actionName]; ok {
+		if err := check(tmplArgs); err != nil {
+			return nil, err
+		}
+	}
+
+	if !hasNumericTemplate(tmplArgs) {
+		sampleArgs, _ := expandTemplatedArgs(nil, tmplArgs, nil)
+		if _, err := r.New(actionName, sampleArgs...); err != nil {
+			return nil, err
+		}
+	}
+
+	// Actions built for different expanded arguments are separate; processes
+	// started by them share a key to remain subject to instance policies.
+	key := newSupervisorKey()
+	cache := newActionCache(templatedActionsCap)
+	templated := func(actx context.Context, ev Trigger) error {
+		expArgs, err := expandTemplatedArgs(actx, tmplArgs, &ctx)
+		if err != nil {
+			return err
+		}
+		argsKey := fmt.Sprintf("%#v", expArgs)
+		a, ok := cache.get(argsKey)
+		if !ok {
+			if a, err = r.New(actionName, expArgs...); err != nil {
+				return err
+			}
+			cache.set(argsKey, a)
+		}
+		return runAction(withSupervisorKey(actx, key), a, ev)
+	}
+	return templated, nil
+}
+
+// templatedActionsCap is the max number of built actions to remember per
+// templated action.
+const templatedActionsCap = 16
+
+// hasNumericTemplate checks whether parsed templated arguments contain any
+// numeric templates.
+func hasNumericTemplate(args []interface{}) bool {
+	for _, arg := range args {
+		switch t := arg.(type) {
+		case *Template:
+			if t.IsNumeric() {
+				return true
+			}
+		case []interface{}:
+			if hasNumericTemplate(t) {
+				return true
+			}
+		case map[string]interface{}:
+			for _, v := range t {
+				if hasNumericTemplate([]interface{}{v}) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// parseTemplatedArg parses the templates of a (nested) argument, and reports
+// whether it contains any.
+func parseTemplatedArg(arg interface{}) (interface{}, bool, error) {
+	switch t := arg.(type) {
+	case string:
+		if !IsTemplate(t) {
+			return t, false, nil
+		}
+		tmpl, err := ParseTemplate(t)
+		if err != nil {
+			return nil, false, err
+		}
+		return tmpl, true, nil
+	case []interface{}:
+		list := make([]interface{}, len(t))
+		isTemplated := false
+		for i, v := range t {
+			tv, ok, err := parseTemplatedArg(v)
+			if err != nil {
+				return nil, false, err
+			}
+			list[i] = tv
+			isTemplated = isTemplated || ok
+		}
+		return list, isTemplated, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		isTemplated := false
+		for k, v := range t {
+			tv, ok, err := parseTemplatedArg(v)
+			if err != nil {
+				return nil, false, err
+			}
+			m[k] = tv
+			isTemplated = isTemplated || ok
+		}
+		return m, isTemplated, nil
+	}
+	return arg, false, nil
+}
+
+// expandTemplatedArgs expands parsed templated arguments.
+//
+// When tc is nil, templates are replaced by sample values, i.e. their source.
+// Numeric templates must be expanded via tc.
+func expandTemplatedArgs(
+	ctx context.Context,
+	args []interface{},
+	tc *TemplateContext,
+) ([]interface{}, error) {
+	exp := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := expandTemplatedArg(ctx, arg, tc)
+		if err != nil {
+			return nil, err
+		}
+		exp[i] = v
+	}
+	return exp, nil
+}
+
+func expandTemplatedArg(
+	ctx context.Context,
+	arg interface{},
+	tc *TemplateContext,
+) (interface{}, error) {
+	switch t := arg.(type) {
+	case *Template:
+		if tc == nil {
+			return t.String(), nil
+		}
+		str, err := t.Expand(ctx, *tc)
+		if err != nil || !t.IsNumeric() {
+			return str, err
+		}
+		return strconv.Atoi(str)
+	case []interface{}:
+		return expandTemplatedArgs(ctx, t, tc)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			ev, err := expandTemplatedArg(ctx, v, tc)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = ev
+		}
+		return m, nil
+	}
+	return arg, nil
+}
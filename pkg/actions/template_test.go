@@ -0,0 +1,320 @@
+package actions_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/echocrow/Mouser/pkg/actions/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTemplateContext() actions.TemplateContext {
+	fg := new(actions.FakeForeground)
+	fg.Set(actions.ForegroundApp{
+		Path:  "/Applications/Foo.app",
+		Title: "Foo Title",
+	})
+
+	drv := new(mocks.Driver)
+	drv.On("PointerPos").Return(12, 34)
+	drv.On("ReadClipboard").Return("clipped", nil)
+
+	env := map[string]string{"HOME": "/home/foo", "EMPTY": ""}
+
+	return actions.TemplateContext{
+		Foreground: fg,
+		Driver:     drv,
+		Now: func() time.Time {
+			return time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+		},
+		Getenv: func(key string) string { return env[key] },
+	}
+}
+
+func TestTemplateExpand(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestTemplateContext()
+
+	tests := []struct {
+		tmpl   string
+		want   string
+		wantOk bool
+	}{
+		{"", "", true},
+		{"foo", "foo", true},
+		{"{{env.HOME}}/bar", "/home/foo/bar", true},
+		{"{{ env.HOME }}", "/home/foo", true},
+		{"[{{env.EMPTY}}{{env.UNSET}}]", "[]", true},
+		{"{{app.path}}", "/Applications/Foo.app", true},
+		{"{{app.name}}", "Foo", true},
+		{"{{window.title}}!", "Foo Title!", true},
+		{"{{pointer.x}},{{pointer.y}}", "12,34", true},
+		{"<{{clipboard}}>", "<clipped>", true},
+		{"{{date}}", "2021-02-03", true},
+		{`{{date "15:04"}}`, "04:05", true},
+		{"{{date `Jan 2`}}", "Feb 3", true},
+		{`{{cmd "echo foo bar"}}`, "foo bar", true},
+		{`{{cmd "printf 'a}}b\n\n'"}}`, "a}}b", true},
+		{`{{"{{"}}env.HOME}}`, "{{env.HOME}}", true},
+		{`{{"\""}}`, `"`, true},
+
+		{"{{", "", false},
+		{"{{env.HOME", "", false},
+		{"{{}}", "", false},
+		{"{{env}}", "", false},
+		{"{{foo}}", "", false},
+		{"{{app}}", "", false},
+		{"{{app.foo}}", "", false},
+		{"{{clipboard.foo}}", "", false},
+		{"{{window.title \"foo\"}}", "", false},
+		{"{{date foo}}", "", false},
+		{"{{cmd}}", "", false},
+		{`{{"foo}}`, "", false},
+		{`{{cmd "exit 1"}}`, "", false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.tmpl, func(t *testing.T) {
+			t.Parallel()
+			tmpl, err := actions.ParseTemplate(tc.tmpl)
+			if err == nil {
+				var got string
+				got, err = tmpl.Expand(context.Background(), ctx)
+				if tc.wantOk {
+					assert.Equal(t, tc.want, got)
+				}
+			}
+			if tc.wantOk {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestTemplateExpandClipboardError(t *testing.T) {
+	t.Parallel()
+
+	drv := new(mocks.Driver)
+	drv.On("ReadClipboard").Return("", errors.New("no clipboard"))
+
+	tmpl, err := actions.ParseTemplate("{{clipboard}}")
+	require.NoError(t, err)
+	_, err = tmpl.Expand(context.Background(), actions.TemplateContext{Driver: drv})
+	assert.ErrorIs(t, err, actions.ErrTemplateValueFailed)
+}
+
+func TestTemplateExpandCmdCancel(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := actions.ParseTemplate(`{{cmd "sleep 5"}}`)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	start := time.Now()
+	_, err = tmpl.Expand(ctx, actions.TemplateContext{})
+	assert.ErrorIs(t, err, actions.ErrTemplateValueFailed)
+	assert.Less(t, time.Since(start), time.Second*2)
+}
+
+func TestIsTemplate(t *testing.T) {
+	t.Parallel()
+	assert.True(t, actions.IsTemplate("foo {{env.HOME}}"))
+	assert.False(t, actions.IsTemplate("foo { bar }"))
+}
+
+func TestNewTemplated(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestTemplateContext()
+
+	tests := []struct {
+		name   string
+		action string
+		args   []i
+		wantOk bool
+	}{
+		{"creates untemplated action", "io:type", []i{"foo"}, true},
+		{"creates templated action", "io:type", []i{"{{env.HOME}}"}, true},
+		{"rejects invalid template", "io:type", []i{"{{foo}}"}, false},
+		{"rejects invalid args", "io:type", []i{"{{env.HOME}}", 1}, false},
+		{"creates templated required arg", "os:open", []i{"{{clipboard}}"}, true},
+		{"creates numeric templated args", "io:move", []i{"{{pointer.x}}", "{{pointer.y}}"}, true},
+		{"defers validating numeric templated args", "io:click", []i{"left", "{{pointer.x}}"}, true},
+		{"rejects invalid arg types", "io:move", []i{"{{env.HOME}}", 1}, false},
+		{"rejects invalid action", "foo:bar", []i{"{{env.HOME}}"}, false},
+		{"creates templated command args", "os:cmd", []i{"echo", "{{clipboard}}"}, true},
+		{"creates templated script params", "os:cmd", []i{`echo "$1"`, "{{clipboard}}", map[string]i{"shell": "sh"}}, true},
+		{"rejects templated script", "os:cmd", []i{"echo {{clipboard}}", map[string]i{"shell": true}}, false},
+		{"rejects templated cmd params", "os:cmd", []i{"echo", "{{clipboard}}", map[string]i{"shell": "cmd"}}, false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := actions.NewTemplated(tc.action, ctx, tc.args...)
+			if tc.wantOk {
+				assert.NotNil(t, got)
+				assert.NoError(t, err)
+			} else {
+				assert.Nil(t, got)
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestNewTemplatedExpandsOnTrigger(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	env := map[string]string{"OUT": out, "TEXT": "foo"}
+
+	ctx := newTestTemplateContext()
+	ctx.Getenv = func(key string) string { return env[key] }
+
+	a, err := actions.NewTemplated(
+		"os:cmd", ctx,
+		`printf %s "$1" > "$2"`, "{{env.TEXT}}", "{{env.OUT}}",
+		map[string]interface{}{"shell": "sh"},
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, a(context.Background(), actions.Trigger{}))
+	assert.Eventually(t, func() bool {
+		got, _ := os.ReadFile(out)
+		return string(got) == "foo"
+	}, time.Second, time.Millisecond)
+}
+
+func TestNewTemplatedExpandsNestedArgs(t *testing.T) {
+	t.Parallel()
+
+	srv, reqs := newTestHTTPServer(t, 200, 0)
+	env := map[string]string{"URL": srv.URL, "TEXT": "foo"}
+
+	ctx := newTestTemplateContext()
+	ctx.Getenv = func(key string) string { return env[key] }
+
+	a, err := actions.NewTemplated(
+		"http:request", ctx,
+		"{{env.URL}}/bar",
+		map[string]interface{}{
+			"headers": map[string]interface{}{"X-Foo": "{{window.title}}"},
+			"body":    "{{env.TEXT}}",
+		},
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, a(context.Background(), actions.Trigger{}))
+	assert.Equal(t, testHTTPRequest{"POST", "/bar", "Foo Title", "foo"}, <-reqs)
+
+	_, err = actions.NewTemplated(
+		"http:request", ctx,
+		"http://localhost",
+		map[string]interface{}{"body": "{{foo}}"},
+	)
+	assert.Error(t, err)
+}
+
+func TestNewTemplatedNumericArgs(t *testing.T) {
+	t.Parallel()
+
+	tc := newTestTemplateContext()
+	drv := new(actions.FakeDriver)
+	tc.Driver = drv
+	drv.SetPointerPos(12, 34)
+
+	reg := actions.NewRegistry()
+	var got []interface{}
+	require.NoError(t, reg.Register("test:args", func(args ...interface{}) (actions.Action, error) {
+		return func(context.Context, actions.Trigger) error {
+			got = args
+			return nil
+		}, nil
+	}))
+	a, err := reg.NewTemplated("test:args", tc, "{{pointer.x}}", "{{pointer.y}}px")
+	require.NoError(t, err)
+	require.NoError(t, a(context.Background(), actions.Trigger{}))
+	assert.Equal(t, []interface{}{12, "34px"}, got)
+}
+
+func TestNewTemplatedKeepsState(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{}
+	tc := newTestTemplateContext()
+	tc.Getenv = func(key string) string { return env[key] }
+
+	reg := actions.NewRegistry()
+	var got []string
+	require.NoError(t, reg.Register("test:count", func(args ...interface{}) (actions.Action, error) {
+		n := 0
+		return func(context.Context, actions.Trigger) error {
+			n++
+			got = append(got, fmt.Sprintf("%s#%d", args[0], n))
+			return nil
+		}, nil
+	}))
+	a, err := reg.NewTemplated("test:count", tc, "{{env.TEXT}}")
+	require.NoError(t, err)
+
+	for _, text := range []string{"a", "a", "b", "a"} {
+		env["TEXT"] = text
+		require.NoError(t, a(context.Background(), actions.Trigger{}))
+	}
+	assert.Equal(t, []string{"a#1", "a#2", "b#1", "a#3"}, got)
+}
+
+func TestNewTemplatedKeepsPolicy(t *testing.T) {
+	t.Parallel()
+
+	out := filepath.Join(t.TempDir(), "out")
+	env := map[string]string{"OUT": out}
+	tc := newTestTemplateContext()
+	tc.Getenv = func(key string) string { return env[key] }
+
+	a, err := actions.NewTemplated(
+		"os:cmd", tc,
+		"echo x >> \"$1\"; sleep 0.2", "{{env.OUT}}",
+		map[string]interface{}{"shell": true, "policy": "single-instance"},
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, a(context.Background(), actions.Trigger{}))
+	time.Sleep(time.Millisecond * 50)
+	require.NoError(t, a(context.Background(), actions.Trigger{}))
+	time.Sleep(time.Millisecond * 300)
+	got, _ := os.ReadFile(out)
+	assert.Equal(t, "x\n", string(got))
+}
+
+func TestNewTemplatedScriptParams(t *testing.T) {
+	t.Parallel()
+
+	out := filepath.Join(t.TempDir(), "out")
+	tc := newTestTemplateContext()
+	drv := new(actions.FakeDriver)
+	drv.WriteClipboard(`"; echo injected; "`)
+	tc.Driver = drv
+
+	a, err := actions.NewTemplated(
+		"os:cmd", tc,
+		`printf %s "$1" > "$2"`, "{{clipboard}}", out,
+		map[string]interface{}{"shell": "sh", "wait": true},
+	)
+	require.NoError(t, err)
+	require.NoError(t, a(context.Background(), actions.Trigger{}))
+	got, _ := os.ReadFile(out)
+	assert.Equal(t, `"; echo injected; "`, string(got))
+}
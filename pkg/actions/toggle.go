@@ -1,48 +1,84 @@
 package actions
 
 import (
+	"context"
 	"sync"
 	"time"
 )
 
 // NewToggle creates a toggable action.
+//
+// The on action triggers action, and then keeps repeating it until the off
+// action is triggered, the on context is cancelled, or a repetition failed.
+// Repetitions run concurrently, i.e. a slow action does not delay subsequent
+// repetitions. Failed repetitions are reported to the failure handler (see
+// SetFailureHandler).
 func NewToggle(
 	action Action,
 	initDelay time.Duration,
 	repeatDelay time.Duration,
+) (on, off Action) {
+	return NewToggleCustom(action, initDelay, repeatDelay, SystemClock{})
+}
+
+// NewToggleCustom creates a toggable action based on a custom clock.
+func NewToggleCustom(
+	action Action,
+	initDelay time.Duration,
+	repeatDelay time.Duration,
+	clock Clock,
 ) (on, off Action) {
 	mu := sync.Mutex{}
-	isRunning := false
-	stopCh := make(chan struct{})
-	on = func() {
+	var stopCh chan struct{}
+	stop := func(ch chan struct{}) {
 		mu.Lock()
 		defer mu.Unlock()
-		if isRunning {
-			return
+		if stopCh == ch && ch != nil {
+			close(ch)
+			stopCh = nil
 		}
-		isRunning = true
-		go func() {
-			go action()
-			time.Sleep(initDelay)
-			for {
-				select {
-				case <-stopCh:
-					return
-				default:
-					go action()
-					time.Sleep(repeatDelay)
-				}
-			}
-		}()
 	}
-	off = func() {
+	on = func(ctx context.Context, ev Trigger) error {
 		mu.Lock()
-		defer mu.Unlock()
-		if !isRunning {
-			return
+		if stopCh != nil {
+			mu.Unlock()
+			return nil
+		}
+		ch := make(chan struct{})
+		stopCh = ch
+		mu.Unlock()
+
+		if err := action(ctx, ev); err != nil {
+			stop(ch)
+			return err
+		}
+		var repeat func()
+		repeat = func() {
+			select {
+			case <-ch:
+				return
+			case <-ctx.Done():
+				stop(ch)
+				return
+			default:
+			}
+			go func() {
+				if err := action(ctx, ev); err != nil && ctx.Err() == nil {
+					reportFailure("toggle", err)
+					stop(ch)
+				}
+			}()
+			clock.AfterFunc(repeatDelay, repeat)
 		}
-		isRunning = false
-		stopCh <- struct{}{}
+		clock.AfterFunc(initDelay, repeat)
+		return nil
+	}
+	off = func(context.Context, Trigger) error {
+		mu.Lock()
+		ch := stopCh
+		mu.Unlock()
+		stop(ch)
+		return nil
 	}
 	return
 }
@@ -1,11 +1,14 @@
 package actions_test
 
 import (
+	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/echocrow/Mouser/pkg/actions"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateToggle(t *testing.T) {
@@ -20,25 +23,69 @@ func TestCreateToggle(t *testing.T) {
 	called := uint(0)
 	maxCalls := uint(3)
 	stopped := false
-	action := func() {
+	action := newTestAction(func() {
 		mu.Lock()
 		defer mu.Unlock()
 		if !stopped {
 			called++
 			if called >= maxCalls {
 				stopped = true
-				off()
+				off(context.Background(), actions.Trigger{})
 				done <- struct{}{}
 			}
 		}
-	}
+	})
 
 	on, off = actions.NewToggle(action, 0, 0)
 	assert.NotNil(t, on, `want "on" action`)
 	assert.NotNil(t, off, `want "off" action`)
 
 	assert.Equal(t, uint(0), called, `want no calls before "on"`)
-	on()
+	on(context.Background(), actions.Trigger{})
 	<-done
 	assert.Equal(t, maxCalls, called, "want right number of action calls")
 }
+
+func TestToggleCancel(t *testing.T) {
+	t.Parallel()
+
+	clk := actions.NewFakeClock(time.Unix(0, 0))
+	calls := make(chan struct{}, 16)
+	on, _ := actions.NewToggleCustom(newTestAction(func() { calls <- struct{}{} }), time.Second, time.Second, clk)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, on(ctx, actions.Trigger{}))
+	<-calls
+	clk.Advance(time.Second)
+	<-calls
+	cancel()
+	clk.Advance(time.Second * 5)
+	assert.Never(t, func() bool { return len(calls) > 0 }, time.Millisecond*20, time.Millisecond)
+}
+
+func TestToggleRepeatsConcurrently(t *testing.T) {
+	t.Parallel()
+
+	clk := actions.NewFakeClock(time.Unix(0, 0))
+	calls := make(chan struct{}, 16)
+	release := make(chan struct{})
+	first := true
+	action := func(context.Context, actions.Trigger) error {
+		if first {
+			first = false
+			return nil
+		}
+		calls <- struct{}{}
+		<-release
+		return nil
+	}
+	on, off := actions.NewToggleCustom(action, time.Second, time.Second, clk)
+	require.NoError(t, on(context.Background(), actions.Trigger{}))
+	clk.Advance(time.Second * 3)
+	for i := 0; i < 3; i++ {
+		<-calls
+	}
+	require.NoError(t, off(context.Background(), actions.Trigger{}))
+	close(release)
+	clk.Advance(time.Second * 3)
+	assert.Empty(t, calls)
+}
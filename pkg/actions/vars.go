@@ -0,0 +1,224 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Variable errors raised by package actions.
+var (
+	ErrInvalidVarName = errors.New("variable name is invalid")
+	ErrVarNotNumber   = errors.New("variable is not a number")
+	ErrVarNotBool     = errors.New("variable is not a boolean")
+)
+
+// Vars holds a store of named state variables.
+//
+// Variable values are strings; unset variables are empty.
+type Vars struct {
+	vals map[string]string
+	path string
+	mx   sync.Mutex
+}
+
+// NewVars creates a new, empty variable store.
+func NewVars() *Vars {
+	return &Vars{vals: make(map[string]string)}
+}
+
+var (
+	defaultVars     *Vars
+	defaultVarsOnce sync.Once
+)
+
+// DefaultVars gets the shared default variable store.
+func DefaultVars() *Vars {
+	defaultVarsOnce.Do(func() {
+		defaultVars = NewVars()
+	})
+	return defaultVars
+}
+
+// Persist loads variables from the JSON file at path, and saves subsequent
+// changes to it. A missing file is treated as an empty store.
+func (v *Vars) Persist(path string) error {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	} else if err == nil {
+		vals := make(map[string]string)
+		if err := json.Unmarshal(data, &vals); err != nil {
+			return fmt.Errorf("invalid variables file \"%s\": %w", path, err)
+		}
+		for name, val := range vals {
+			v.vals[name] = val
+		}
+	}
+	v.path = path
+	return nil
+}
+
+// save writes the variables to the persistence file, if any.
+func (v *Vars) save() error {
+	if v.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(v.vals, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(v.path), 0o755); err != nil {
+		return err
+	}
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, v.path)
+}
+
+// Get gets the value of variable name.
+func (v *Vars) Get(name string) string {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	return v.vals[name]
+}
+
+// Set sets variable name to value.
+func (v *Vars) Set(name, value string) error {
+	return v.update(name, func(string) (string, error) {
+		return value, nil
+	})
+}
+
+// Inc increments numeric variable name by n, and gets its new value. Unset
+// variables count as 0.
+func (v *Vars) Inc(name string, n int) (int, error) {
+	var i int
+	err := v.update(name, func(val string) (string, error) {
+		if val != "" {
+			var err error
+			if i, err = strconv.Atoi(val); err != nil {
+				return "", fmt.Errorf("%w: \"%s\"", ErrVarNotNumber, name)
+			}
+		}
+		i += n
+		return strconv.Itoa(i), nil
+	})
+	return i, err
+}
+
+// Toggle flips boolean variable name, and gets its new value. Unset variables
+// count as false.
+func (v *Vars) Toggle(name string) (bool, error) {
+	var b bool
+	err := v.update(name, func(val string) (string, error) {
+		if val != "" {
+			var err error
+			if b, err = strconv.ParseBool(val); err != nil {
+				return "", fmt.Errorf("%w: \"%s\"", ErrVarNotBool, name)
+			}
+		}
+		b = !b
+		return strconv.FormatBool(b), nil
+	})
+	return b, err
+}
+
+func (v *Vars) update(name string, fn func(string) (string, error)) error {
+	if name == "" {
+		return ErrInvalidVarName
+	}
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	val, err := fn(v.vals[name])
+	if err != nil {
+		return err
+	}
+	if v.vals[name] == val {
+		return nil
+	}
+	v.vals[name] = val
+	return v.save()
+}
+
+// varsOrDefault gets v, or the default variable store if v is nil.
+func varsOrDefault(v *Vars) *Vars {
+	if v == nil {
+		return DefaultVars()
+	}
+	return v
+}
+
+// NewVarSet creates a new action setting variable name to value.
+//
+// A nil v uses the default store (see DefaultVars).
+func NewVarSet(v *Vars, name, value string) (Action, error) {
+	if name == "" {
+		return nil, ErrInvalidVarName
+	}
+	v = varsOrDefault(v)
+	return func(context.Context, Trigger) error {
+		return v.Set(name, value)
+	}, nil
+}
+
+// NewVarInc creates a new action incrementing numeric variable name by n.
+//
+// A nil v uses the default store (see DefaultVars).
+func NewVarInc(v *Vars, name string, n int) (Action, error) {
+	if name == "" {
+		return nil, ErrInvalidVarName
+	}
+	v = varsOrDefault(v)
+	return func(context.Context, Trigger) error {
+		_, err := v.Inc(name, n)
+		return err
+	}, nil
+}
+
+// NewVarToggle creates a new action flipping boolean variable name.
+//
+// A nil v uses the default store (see DefaultVars).
+func NewVarToggle(v *Vars, name string) (Action, error) {
+	if name == "" {
+		return nil, ErrInvalidVarName
+	}
+	v = varsOrDefault(v)
+	return func(context.Context, Trigger) error {
+		_, err := v.Toggle(name)
+		return err
+	}, nil
+}
+
+// NewVarBranch creates a variable-based action branch.
+//
+// The branch matching the current value of variable name is triggered, or
+// fallback if no branch matches. A nil v uses the default store (see
+// DefaultVars).
+func NewVarBranch(
+	v *Vars,
+	name string,
+	branches map[string]Action,
+	fallback Action,
+) (Action, error) {
+	if name == "" {
+		return nil, ErrInvalidVarName
+	}
+	v = varsOrDefault(v)
+	return func(ctx context.Context, ev Trigger) error {
+		action, ok := branches[v.Get(name)]
+		if !ok {
+			action = fallback
+		}
+		return runAction(ctx, action, ev)
+	}, nil
+}
@@ -0,0 +1,99 @@
+package actions_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVars(t *testing.T) {
+	t.Parallel()
+
+	v := actions.NewVars()
+	assert.Equal(t, "", v.Get("foo"))
+
+	require.NoError(t, v.Set("foo", "bar"))
+	assert.Equal(t, "bar", v.Get("foo"))
+
+	n, err := v.Inc("count", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = v.Inc("count", 5)
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.Equal(t, "6", v.Get("count"))
+	_, err = v.Inc("foo", 1)
+	assert.ErrorIs(t, err, actions.ErrVarNotNumber)
+
+	b, err := v.Toggle("flag")
+	require.NoError(t, err)
+	assert.True(t, b)
+	b, err = v.Toggle("flag")
+	require.NoError(t, err)
+	assert.False(t, b)
+	assert.Equal(t, "false", v.Get("flag"))
+	_, err = v.Toggle("foo")
+	assert.ErrorIs(t, err, actions.ErrVarNotBool)
+
+	assert.ErrorIs(t, v.Set("", "bar"), actions.ErrInvalidVarName)
+}
+
+func TestVarsPersist(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sub", "vars.json")
+
+	v := actions.NewVars()
+	require.NoError(t, v.Persist(path))
+	require.NoError(t, v.Set("foo", "bar"))
+	_, err := v.Inc("count", 2)
+	require.NoError(t, err)
+
+	v2 := actions.NewVars()
+	require.NoError(t, v2.Persist(path))
+	assert.Equal(t, "bar", v2.Get("foo"))
+	assert.Equal(t, "2", v2.Get("count"))
+
+	require.NoError(t, os.WriteFile(path, []byte("nope"), 0o644))
+	assert.Error(t, actions.NewVars().Persist(path))
+}
+
+func TestVarActions(t *testing.T) {
+	t.Parallel()
+
+	v := actions.NewVars()
+	var got []string
+	record := func(name string) actions.Action {
+		return newTestAction(func() { got = append(got, name) })
+	}
+
+	branch, err := actions.NewVarBranch(v, "mode", map[string]actions.Action{
+		"":     record("first"),
+		"true": record("second"),
+	}, record("fallback"))
+	require.NoError(t, err)
+	toggle, err := actions.NewVarToggle(v, "mode")
+	require.NoError(t, err)
+	set, err := actions.NewVarSet(v, "mode", "foo")
+	require.NoError(t, err)
+	inc, err := actions.NewVarInc(v, "count", 2)
+	require.NoError(t, err)
+
+	branch(context.Background(), actions.Trigger{})
+	toggle(context.Background(), actions.Trigger{})
+	branch(context.Background(), actions.Trigger{})
+	set(context.Background(), actions.Trigger{})
+	branch(context.Background(), actions.Trigger{})
+	inc(context.Background(), actions.Trigger{})
+	inc(context.Background(), actions.Trigger{})
+	assert.Equal(t, []string{"first", "second", "fallback"}, got)
+	assert.Equal(t, "4", v.Get("count"))
+
+	_, err = actions.NewVarBranch(v, "", nil, nil)
+	assert.ErrorIs(t, err, actions.ErrInvalidVarName)
+}
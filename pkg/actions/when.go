@@ -0,0 +1,200 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// cmdConditionTimeout limits how long command conditions may run before they
+// are killed and considered unmet.
+const cmdConditionTimeout = time.Second * 5
+
+// Condition describes a predicate of a conditional action, evaluated with the
+// context of the triggered action.
+type Condition func(ctx context.Context) bool
+
+// Condition errors raised by package actions.
+var (
+	ErrInvalidTimeWindow = errors.New("time window is invalid")
+	ErrInvalidWeekday    = errors.New("weekday is invalid")
+)
+
+// NewWhen creates a condition-dependent action branch.
+//
+// The then action is triggered when all conditions are met, otherwise the
+// otherwise action is triggered.
+func NewWhen(
+	conds []Condition,
+	then Action,
+	otherwise Action,
+) Action {
+	return func(ctx context.Context, ev Trigger) error {
+		action := then
+		for _, cond := range conds {
+			if !cond(ctx) {
+				action = otherwise
+				break
+			}
+		}
+		return runAction(ctx, action, ev)
+	}
+}
+
+// NewTimeCondition creates a condition that is met within a given daily time
+// window, e.g. "09:00-17:30".
+//
+// Windows wrapping around midnight (e.g. "22:00-06:00") are supported. The
+// window start is inclusive, the window end is exclusive.
+func NewTimeCondition(window string) (Condition, error) {
+	return NewTimeConditionCustom(window, time.Now)
+}
+
+// NewTimeConditionCustom creates a time window condition based on a custom
+// clock.
+func NewTimeConditionCustom(
+	window string,
+	now func() time.Time,
+) (Condition, error) {
+	fromStr, toStr, ok := strings.Cut(window, "-")
+	if !ok {
+		return nil, ErrInvalidTimeWindow
+	}
+	from, err := parseTimeOfDay(fromStr)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeOfDay(toStr)
+	if err != nil {
+		return nil, err
+	}
+	cond := func(context.Context) bool {
+		t := now()
+		d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if from <= to {
+			return from <= d && d < to
+		}
+		return from <= d || d < to
+	}
+	return cond, nil
+}
+
+func parseTimeOfDay(str string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(str))
+	if err != nil {
+		return 0, ErrInvalidTimeWindow
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// NewWeekdayCondition creates a condition that is met on any of the given
+// weekdays, e.g. "mon", "Tuesday" or "mon-fri".
+func NewWeekdayCondition(days []string) (Condition, error) {
+	return NewWeekdayConditionCustom(days, time.Now)
+}
+
+// NewWeekdayConditionCustom creates a weekday condition based on a custom
+// clock.
+func NewWeekdayConditionCustom(
+	days []string,
+	now func() time.Time,
+) (Condition, error) {
+	if len(days) == 0 {
+		return nil, ErrInvalidWeekday
+	}
+	var wds [7]bool
+	for _, day := range days {
+		fromStr, toStr, isRange := strings.Cut(day, "-")
+		from, err := parseWeekday(fromStr)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = parseWeekday(toStr); err != nil {
+				return nil, err
+			}
+		}
+		for wd := from; ; wd = (wd + 1) % 7 {
+			wds[wd] = true
+			if wd == to {
+				break
+			}
+		}
+	}
+	cond := func(context.Context) bool {
+		return wds[now().Weekday()]
+	}
+	return cond, nil
+}
+
+func parseWeekday(str string) (time.Weekday, error) {
+	str = strings.ToLower(strings.TrimSpace(str))
+	if len(str) >= 3 {
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			if strings.HasPrefix(strings.ToLower(wd.String()), str) {
+				return wd, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: \"%s\"", ErrInvalidWeekday, str)
+}
+
+// NewHostCondition creates a condition that is met when the current hostname
+// matches host.
+//
+// Hostnames are compared case-insensitively, and host may omit the domain
+// part of the current hostname.
+func NewHostCondition(host string) Condition {
+	return func(context.Context) bool {
+		curr, err := os.Hostname()
+		if err != nil {
+			return false
+		}
+		if strings.EqualFold(curr, host) {
+			return true
+		}
+		short, _, _ := strings.Cut(curr, ".")
+		return strings.EqualFold(short, host)
+	}
+}
+
+// NewEnvCondition creates a condition based on an environment variable.
+//
+// Given "NAME", the condition is met when the variable is set and not empty.
+// Given "NAME=value", the condition is met when the variable equals value.
+func NewEnvCondition(env string) Condition {
+	name, want, hasValue := strings.Cut(env, "=")
+	return func(context.Context) bool {
+		val := os.Getenv(name)
+		if hasValue {
+			return val == want
+		}
+		return val != ""
+	}
+}
+
+// NewFileCondition creates a condition that is met when the file at path
+// exists.
+func NewFileCondition(path string) Condition {
+	return func(context.Context) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+}
+
+// NewCmdCondition creates a condition that is met when the given command exits
+// successfully.
+//
+// The command is killed once the action is cancelled, or after a timeout, in
+// which case the condition is not met.
+func NewCmdCondition(name string, args ...string) Condition {
+	return func(ctx context.Context) bool {
+		ctx, cancel := context.WithTimeout(ctx, cmdConditionTimeout)
+		defer cancel()
+		return newKillableCmd(ctx, name, args...).Run() == nil
+	}
+}
@@ -0,0 +1,203 @@
+package actions_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cond = actions.Condition
+
+func TestNewWhen(t *testing.T) {
+	const (
+		then     = "then"
+		fallback = "else"
+	)
+
+	calls := make(chan string, 2)
+
+	var (
+		thenAct act  = newTestAction(func() { calls <- then })
+		elseAct act  = newTestAction(func() { calls <- fallback })
+		yes     cond = func(context.Context) bool { return true }
+		no      cond = func(context.Context) bool { return false }
+	)
+
+	tests := []struct {
+		name     string
+		conds    []cond
+		thenAct  act
+		elseAct  act
+		wantCall string
+	}{
+		{"calls then without conditions", nil, thenAct, elseAct, then},
+		{"calls then on met condition", []cond{yes}, thenAct, elseAct, then},
+		{"calls then on met conditions", []cond{yes, yes}, thenAct, elseAct, then},
+		{"calls else on unmet condition", []cond{no}, thenAct, elseAct, fallback},
+		{"calls else on partially met conditions", []cond{yes, no}, thenAct, elseAct, fallback},
+		{"skips nil then", []cond{yes}, nil, elseAct, ""},
+		{"skips nil else", []cond{no}, thenAct, nil, ""},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			whenAction := actions.NewWhen(tc.conds, tc.thenAct, tc.elseAct)
+			wantOk := tc.wantCall != ""
+			assertActionCalls(t, whenAction, wantOk, tc.wantCall, calls)
+		})
+	}
+}
+
+func TestNewTimeConditionCustom(t *testing.T) {
+	t.Parallel()
+
+	at := func(h, m int) time.Time {
+		return time.Date(2021, 1, 1, h, m, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		window string
+		now    time.Time
+		wantOk bool
+		want   bool
+	}{
+		{"09:00-17:00", at(9, 0), true, true},
+		{"09:00-17:00", at(12, 30), true, true},
+		{"09:00-17:00", at(16, 59), true, true},
+		{"09:00-17:00", at(17, 0), true, false},
+		{"09:00-17:00", at(8, 59), true, false},
+		{"9:30 - 10:15", at(10, 0), true, true},
+		{"22:00-06:00", at(23, 0), true, true},
+		{"22:00-06:00", at(3, 0), true, true},
+		{"22:00-06:00", at(12, 0), true, false},
+		{"", at(12, 0), false, false},
+		{"09:00", at(12, 0), false, false},
+		{"09:00-25:00", at(12, 0), false, false},
+		{"foo-bar", at(12, 0), false, false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.window, func(t *testing.T) {
+			t.Parallel()
+			now := func() time.Time { return tc.now }
+			c, err := actions.NewTimeConditionCustom(tc.window, now)
+			if !tc.wantOk {
+				assert.Nil(t, c)
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, c(context.Background()))
+		})
+	}
+}
+
+func TestNewWeekdayConditionCustom(t *testing.T) {
+	t.Parallel()
+
+	// 2021-01-04 is a Monday.
+	on := func(wd time.Weekday) time.Time {
+		return time.Date(2021, 1, 4+int(wd+6)%7, 12, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		days   []string
+		now    time.Weekday
+		wantOk bool
+		want   bool
+	}{
+		{"matches short day", []string{"mon"}, time.Monday, true, true},
+		{"matches long day", []string{"Tuesday"}, time.Tuesday, true, true},
+		{"skips other day", []string{"mon"}, time.Tuesday, true, false},
+		{"matches any day", []string{"mon", "wed"}, time.Wednesday, true, true},
+		{"matches range", []string{"mon-fri"}, time.Thursday, true, true},
+		{"skips outside range", []string{"mon-fri"}, time.Sunday, true, false},
+		{"matches wrapping range", []string{"fri-mon"}, time.Sunday, true, true},
+		{"rejects empty list", nil, time.Monday, false, false},
+		{"rejects short name", []string{"mo"}, time.Monday, false, false},
+		{"rejects unknown name", []string{"someday"}, time.Monday, false, false},
+		{"rejects bad range", []string{"mon-foo"}, time.Monday, false, false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			now := func() time.Time { return on(tc.now) }
+			c, err := actions.NewWeekdayConditionCustom(tc.days, now)
+			if !tc.wantOk {
+				assert.Nil(t, c)
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, c(context.Background()))
+		})
+	}
+}
+
+func TestNewHostCondition(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	assert.True(t, actions.NewHostCondition(host)(ctx))
+	assert.False(t, actions.NewHostCondition(host+"-other")(ctx))
+}
+
+func TestNewEnvCondition(t *testing.T) {
+	t.Setenv("MOUSER_TEST_FOO", "foo")
+	t.Setenv("MOUSER_TEST_EMPTY", "")
+	ctx := context.Background()
+
+	assert.True(t, actions.NewEnvCondition("MOUSER_TEST_FOO")(ctx))
+	assert.True(t, actions.NewEnvCondition("MOUSER_TEST_FOO=foo")(ctx))
+	assert.False(t, actions.NewEnvCondition("MOUSER_TEST_FOO=bar")(ctx))
+	assert.False(t, actions.NewEnvCondition("MOUSER_TEST_EMPTY")(ctx))
+	assert.True(t, actions.NewEnvCondition("MOUSER_TEST_EMPTY=")(ctx))
+	assert.False(t, actions.NewEnvCondition("MOUSER_TEST_UNSET")(ctx))
+}
+
+func TestNewFileCondition(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo")
+	c := actions.NewFileCondition(path)
+	assert.False(t, c(context.Background()))
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	assert.True(t, c(context.Background()))
+}
+
+func TestNewCmdCondition(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	assert.True(t, actions.NewCmdCondition("true")(ctx))
+	assert.False(t, actions.NewCmdCondition("false")(ctx))
+	assert.False(t, actions.NewCmdCondition("mouser-missing-cmd")(ctx))
+}
+
+func TestNewCmdConditionCancel(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	start := time.Now()
+	assert.False(t, actions.NewCmdCondition("sleep", "5")(ctx))
+	assert.Less(t, time.Since(start), time.Second*2)
+}
+
+func TestNewWhenError(t *testing.T) {
+	t.Parallel()
+
+	errFoo := errors.New("foo")
+	failing := func(context.Context, actions.Trigger) error { return errFoo }
+	yes := func(context.Context) bool { return true }
+	whenAction := actions.NewWhen([]cond{yes}, failing, nil)
+	assert.ErrorIs(t, whenAction(context.Background(), actions.Trigger{}), errFoo)
+}
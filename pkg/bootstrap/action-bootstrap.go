@@ -1,8 +1,10 @@
 package bootstrap
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"os"
 	"strings"
 	"time"
@@ -20,6 +22,7 @@ const (
 
 const (
 	userHomeDirAlias = "~" + string(os.PathSeparator)
+	appGlobPrefix    = "glob:"
 )
 
 var (
@@ -36,14 +39,21 @@ func newLazyAction(aRef config.ActionRef) lazyAction {
 }
 
 type actionsRepo struct {
-	r  map[string]*lazyAction
-	as map[string]actions.Action
-	s  config.Settings
+	r   map[string]*lazyAction
+	as  map[string]actions.Action
+	s   config.Settings
+	fp  actions.ForegroundProvider
+	tc  actions.TemplateContext
+	reg *actions.Registry
+	clk actions.Clock
 }
 
 func newActionsRepo(
 	aRefs map[string]config.ActionRef,
 	s config.Settings,
+	fp actions.ForegroundProvider,
+	reg *actions.Registry,
+	clk actions.Clock,
 ) actionsRepo {
 	r := make(map[string]*lazyAction, len(aRefs))
 	for name, aRef := range aRefs {
@@ -51,9 +61,18 @@ func newActionsRepo(
 		r[name] = &la
 	}
 	return actionsRepo{
-		r:  r,
-		as: make(map[string]actions.Action),
-		s:  s,
+		r:   r,
+		as:  make(map[string]actions.Action),
+		s:   s,
+		fp:  fp,
+		reg: reg,
+		clk: clk,
+		tc: actions.TemplateContext{
+			Foreground: fp,
+			Driver:     actions.DefaultDriver(),
+			Now:        clk.Now,
+			Getenv:     os.Getenv,
+		},
 	}
 }
 
@@ -66,12 +85,30 @@ func (ar actionsRepo) get(
 	case config.BasicAction:
 		a, err = ar.resolveActionName(ac.Name, ac.Args)
 		name = ac.Name
+	case config.ThrottleAction:
+		a, err = ar.resolveThrottleAction(ac)
+		name = "(throttle)"
+	case config.DebounceAction:
+		a, err = ar.resolveDebounceAction(ac)
+		name = "(debounce)"
+	case config.ConfirmAction:
+		a, err = ar.resolveConfirmAction(ac)
+		name = "(confirm)"
+	case config.CycleAction:
+		a, err = ar.resolveCycleAction(ac)
+		name = "(cycle)"
 	case config.AppBranchAction:
 		a, err = ar.resolveAppBranchAction(ac)
 		name = "(app-branch)"
 	case config.RequireAppAction:
 		a, err = ar.resolveRequireAppAction(ac)
 		name = "(require-app)"
+	case config.WhenAction:
+		a, err = ar.resolveWhenAction(ac)
+		name = "(when)"
+	case config.VarBranchAction:
+		a, err = ar.resolveVarBranchAction(ac)
+		name = "(var-branch)"
 	case nil:
 		return nil, "(empty-action)", nil
 	default:
@@ -123,7 +160,7 @@ func (ar actionsRepo) resolveActionName(
 		}
 	}
 
-	return actions.New(name, args...)
+	return ar.reg.NewTemplated(name, ar.tc, args...)
 }
 
 func (ar actionsRepo) getToggleName(name string) string {
@@ -141,7 +178,7 @@ func (ar actionsRepo) resolveToggle(name string) error {
 		return err
 	}
 
-	on, off := actions.NewToggle(a, initDelay, repeatDelay)
+	on, off := actions.NewToggleCustom(a, initDelay, repeatDelay, ar.clk)
 	onName := name + toggleOnSuffix
 	offName := name + toggleOffSuffix
 	ar.as[onName] = on
@@ -169,6 +206,58 @@ func (ar actionsRepo) resolveToggleBaseAction(
 	return
 }
 
+func (ar actionsRepo) resolveThrottleAction(
+	ac config.ThrottleAction,
+) (actions.Action, error) {
+	a, err := ar.getNested(ac.Action)
+	if err != nil {
+		return nil, err
+	}
+	return actions.NewThrottleCustom(a, ac.Cooldown.Duration(), ac.Leading, ac.Trailing, ar.clk)
+}
+
+func (ar actionsRepo) resolveDebounceAction(
+	ac config.DebounceAction,
+) (actions.Action, error) {
+	a, err := ar.getNested(ac.Action)
+	if err != nil {
+		return nil, err
+	}
+	return actions.NewDebounceCustom(a, ac.Cooldown.Duration(), ac.Leading, ac.Trailing, ar.clk)
+}
+
+func (ar actionsRepo) resolveConfirmAction(
+	ac config.ConfirmAction,
+) (actions.Action, error) {
+	a, aName, err := ar.get(ac.Action)
+	if err != nil {
+		return nil, err
+	}
+	msg := ac.Message
+	if msg == "" {
+		msg = fmt.Sprintf("Trigger again to run %s", aName)
+	}
+	return actions.NewConfirmCustom(a, actions.Confirm{
+		Window:   ac.Window.Duration(),
+		Feedback: ac.Feedback,
+		Message:  msg,
+	}, nil, ar.clk)
+}
+
+func (ar actionsRepo) resolveCycleAction(
+	ac config.CycleAction,
+) (actions.Action, error) {
+	as := make([]actions.Action, len(ac.Actions))
+	for i, aRef := range ac.Actions {
+		a, err := ar.getNested(aRef)
+		if err != nil {
+			return nil, err
+		}
+		as[i] = a
+	}
+	return actions.NewCycleCustom(as, ac.Mode, ac.ResetAfter.Duration(), ar.clk, rand.Intn)
+}
+
 func (ar actionsRepo) resolveAppBranchAction(
 	ac config.AppBranchAction,
 ) (actions.Action, error) {
@@ -178,17 +267,54 @@ func (ar actionsRepo) resolveAppBranchAction(
 		if err != nil {
 			return nil, err
 		}
-		app = expandPath(app)
+		app = expandAppPattern(app)
 		branches[app] = a
 	}
 
+	windows := make([]actions.WindowBranch, len(ac.Windows))
+	for i, wb := range ac.Windows {
+		w, err := ar.resolveWindowBranch(wb)
+		if err != nil {
+			return nil, err
+		}
+		windows[i] = w
+	}
+
 	fallback, err := ar.getNested(ac.Fallback)
 	if err != nil {
 		return nil, err
 	}
 
-	a := actions.NewAppBranch(branches, fallback)
-	return a, nil
+	return actions.NewAppBranch(branches, windows, fallback, ar.fp)
+}
+
+func (ar actionsRepo) resolveWindowBranch(
+	wb config.WindowBranch,
+) (actions.WindowBranch, error) {
+	var title actions.TitleMatcher
+	if wb.Title != "" && wb.TitleRe != "" {
+		return actions.WindowBranch{}, errors.New("window branch must not have both title and title-re")
+	} else if wb.Title != "" {
+		title = actions.NewTitleMatcher(wb.Title)
+	} else if wb.TitleRe != "" {
+		var err error
+		if title, err = actions.NewTitleRegexpMatcher(wb.TitleRe); err != nil {
+			return actions.WindowBranch{}, err
+		}
+	} else {
+		return actions.WindowBranch{}, errors.New("window branch requires title or title-re")
+	}
+
+	a, err := ar.getNested(wb.Do)
+	if err != nil {
+		return actions.WindowBranch{}, err
+	}
+
+	return actions.WindowBranch{
+		App:    expandAppPattern(wb.App),
+		Title:  title,
+		Action: a,
+	}, nil
 }
 
 func (ar actionsRepo) resolveRequireAppAction(
@@ -204,14 +330,98 @@ func (ar actionsRepo) resolveRequireAppAction(
 		return nil, err
 	}
 
-	a := actions.NewRequireApp(expandPath(ac.App), do, fallback)
+	return actions.NewRequireApp(expandAppPattern(ac.App), do, fallback, nil)
+}
+
+func (ar actionsRepo) resolveWhenAction(
+	ac config.WhenAction,
+) (actions.Action, error) {
+	var conds []actions.Condition
+	for _, wc := range ac.If {
+		wcConds, err := makeConditions(wc, ar.clk.Now)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, wcConds...)
+	}
+
+	then, err := ar.getNested(ac.Then)
+	if err != nil {
+		return nil, err
+	}
+
+	otherwise, err := ar.getNested(ac.Else)
+	if err != nil {
+		return nil, err
+	}
+
+	a := actions.NewWhen(conds, then, otherwise)
 	return a, nil
 }
 
+func (ar actionsRepo) resolveVarBranchAction(
+	ac config.VarBranchAction,
+) (actions.Action, error) {
+	branches := make(map[string]actions.Action, len(ac.Branches))
+	for val, aRef := range ac.Branches {
+		a, err := ar.getNested(aRef)
+		if err != nil {
+			return nil, err
+		}
+		branches[val] = a
+	}
+
+	fallback, err := ar.getNested(ac.Fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	return actions.NewVarBranch(nil, ac.Var, branches, fallback)
+}
+
+func makeConditions(
+	wc config.WhenCondition,
+	now func() time.Time,
+) ([]actions.Condition, error) {
+	var conds []actions.Condition
+	if wc.Time != "" {
+		cond, err := actions.NewTimeConditionCustom(wc.Time, now)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if len(wc.Weekday) > 0 {
+		cond, err := actions.NewWeekdayConditionCustom(wc.Weekday, now)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if wc.Host != "" {
+		conds = append(conds, actions.NewHostCondition(wc.Host))
+	}
+	if wc.Env != "" {
+		conds = append(conds, actions.NewEnvCondition(wc.Env))
+	}
+	if wc.File != "" {
+		conds = append(conds, actions.NewFileCondition(expandPath(wc.File)))
+	}
+	if len(wc.Cmd) > 0 {
+		cmdName := expandPath(wc.Cmd[0])
+		conds = append(conds, actions.NewCmdCondition(cmdName, wc.Cmd[1:]...))
+	}
+	if len(conds) == 0 {
+		return nil, errors.New("empty condition in when action")
+	}
+	return conds, nil
+}
+
 // gestureAction holds an action to be triggered by a matching gesture series.
 type gestureAction struct {
 	G gestureMatcher
 	A actions.Action
+	N string
 }
 
 func newLoggedAction(
@@ -219,11 +429,12 @@ func newLoggedAction(
 	name string,
 	logger log.Logger,
 ) actions.Action {
-	return func() {
+	return func(ctx context.Context, ev actions.Trigger) error {
 		logger.Printf(name)
-		if a != nil {
-			a()
+		if a == nil {
+			return nil
 		}
+		return a(ctx, ev)
 	}
 }
 
@@ -244,7 +455,7 @@ func makeGestureAction(
 		a = newLoggedAction(a, aName, logger)
 	}
 
-	return gestureAction{G: gm, A: a}, nil
+	return gestureAction{G: gm, A: a, N: aName}, nil
 }
 
 func expandPath(path string) string {
@@ -253,3 +464,10 @@ func expandPath(path string) string {
 	}
 	return path
 }
+
+func expandAppPattern(pattern string) string {
+	if glob := strings.TrimPrefix(pattern, appGlobPrefix); glob != pattern {
+		return appGlobPrefix + expandPath(glob)
+	}
+	return expandPath(pattern)
+}
@@ -3,9 +3,11 @@ package bootstrap
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 
+	"github.com/echocrow/Mouser/pkg/actions"
 	"github.com/echocrow/Mouser/pkg/config"
-	"github.com/echocrow/Mouser/pkg/hotkeys"
 	"github.com/echocrow/Mouser/pkg/hotkeys/gestures"
 	"github.com/echocrow/Mouser/pkg/hotkeys/gestures/swipes"
 	"github.com/echocrow/Mouser/pkg/hotkeys/hotkey"
@@ -14,40 +16,73 @@ import (
 )
 
 // Bootstrap kickstarts mouser.
-func Bootstrap(conf config.Config) (
+//
+// Actions are resolved from registry reg; a nil reg uses the default registry
+// (see actions.DefaultRegistry). The run function starts the engine and blocks
+// until stop was called.
+//
+// Deprecated: Use NewEngine or NewEngineCustom instead.
+func Bootstrap(conf config.Config, reg *actions.Registry) (
 	run func() error,
 	stop func() error,
 	err error,
 ) {
-	m := hotkeys.DefaultMonitor(conf.Settings.Debug)
-
-	hkGas, err := registerGestures(m, conf)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	var evLogger log.Logger
-	if conf.Settings.Debug {
-		evLogger = log.New("Gesture")
-	}
-
+	e := NewEngineCustom(conf, EngineOptions{Registry: reg})
+	stopped := make(chan struct{})
+	var once sync.Once
 	run = func() error {
-		hkEvs, err := m.Start()
-		if err != nil {
+		if err := e.Start(); err != nil {
 			return err
 		}
-		gestCh := gestures.FromHotkeysCustom(
-			hkEvs,
-			newGesturesConfig(conf.Settings.Gestures),
-			swipes.NewCustomMonitor(newSwipesConfig(conf.Settings.Swipes)),
-		)
-		watchEvs(gestCh, hkGas, evLogger)
+		<-stopped
 		return nil
 	}
+	stop = func() error {
+		err := e.Stop()
+		once.Do(func() { close(stopped) })
+		return err
+	}
+	return run, stop, nil
+}
 
-	stop = m.Stop
+// startPlugins starts plugins and registers their actions in reg.
+func startPlugins(
+	reg *actions.Registry,
+	confs []config.PluginConfig,
+) ([]*actions.Plugin, error) {
+	var plugins []*actions.Plugin
+	for _, pc := range confs {
+		if len(pc.Cmd) == 0 {
+			stopPlugins(plugins)
+			return nil, errors.New("plugin requires a command")
+		}
+		p, err := actions.StartPluginCustom(expandPath(pc.Cmd[0]), pc.Cmd[1:], actions.PluginOptions{
+			Timeout:       pc.Timeout.Duration(),
+			InvokeTimeout: pc.InvokeTimeout.Duration(),
+		})
+		if err != nil {
+			stopPlugins(plugins)
+			return nil, fmt.Errorf("plugin \"%s\" failed to start: %w", pc.Cmd[0], err)
+		}
+		plugins = append(plugins, p)
+		if err := actions.RegisterPlugin(reg, p); err != nil {
+			stopPlugins(plugins)
+			return nil, fmt.Errorf("plugin \"%s\" failed to register: %w", pc.Cmd[0], err)
+		}
+	}
+	return plugins, nil
+}
 
-	return run, stop, nil
+// stopPlugins unregisters the actions of plugins and shuts them down.
+func stopPlugins(plugins []*actions.Plugin) error {
+	var err error
+	for _, p := range plugins {
+		actions.UnregisterPlugin(p)
+		if pErr := p.Shutdown(0); err == nil {
+			err = pErr
+		}
+	}
+	return err
 }
 
 func makeKey(alias config.KeyAlias, mapping config.Mapping) hotkey.KeyName {
@@ -57,19 +92,56 @@ func makeKey(alias config.KeyAlias, mapping config.Mapping) hotkey.KeyName {
 	return hotkey.KeyName(alias)
 }
 
+// hotkeyIDs holds the IDs of registered hotkeys.
+type hotkeyIDs map[hotkey.KeyName]hotkey.ID
+
+// add registers a hotkey once and gets its ID.
+func (ids hotkeyIDs) add(m *monitor.Monitor, key hotkey.KeyName) (hotkey.ID, error) {
+	if hkID, ok := ids[key]; ok {
+		return hkID, nil
+	}
+	hkID, err := m.Hotkeys.Add(key)
+	if err != nil {
+		return 0, err
+	}
+	ids[key] = hkID
+	return hkID, nil
+}
+
+// removeAll unregisters all hotkeys of ids.
+func (ids hotkeyIDs) removeAll(m *monitor.Monitor) {
+	for key, hkID := range ids {
+		m.Hotkeys.Remove(hkID)
+		delete(ids, key)
+	}
+}
+
+// keys maps the hotkey IDs of ids to their keys.
+func (ids hotkeyIDs) keys() map[hotkey.ID]hotkey.KeyName {
+	keys := make(map[hotkey.ID]hotkey.KeyName, len(ids))
+	for key, hkID := range ids {
+		keys[hkID] = key
+	}
+	return keys
+}
+
 func registerGestures(
 	m *monitor.Monitor,
+	hkIDs hotkeyIDs,
 	conf config.Config,
+	fp actions.ForegroundProvider,
+	opts EngineOptions,
+	logger func(name string) log.Logger,
 ) (map[hotkey.ID][]gestureAction, error) {
 	if len(conf.Gestures) == 0 {
-		return nil, errors.New("no hotkeys specified")
+		return nil, nil
 	}
 
-	actRepo := newActionsRepo(conf.Actions, conf.Settings)
+	actRepo := newActionsRepo(conf.Actions, conf.Settings, fp, opts.Registry, opts.Clock)
 
 	var actionLogger log.Logger
 	if conf.Settings.Debug {
-		actionLogger = log.New("Action")
+		actionLogger = logger("Action")
 	}
 
 	hkGas := make(map[hotkey.ID][]gestureAction, len(conf.Gestures))
@@ -85,7 +157,7 @@ func registerGestures(
 			gas[i] = ga
 		}
 
-		hkID, err := m.Hotkeys.Add(key)
+		hkID, err := hkIDs.add(m, key)
 		if err != nil {
 			return nil, err
 		}
@@ -94,26 +166,37 @@ func registerGestures(
 	return hkGas, nil
 }
 
-func watchEvs(
-	gestCh <-chan gestures.Event,
-	hkGas map[hotkey.ID][]gestureAction,
-	logger log.Logger,
-) {
-	for event := range gestCh {
-		if logger != nil {
-			logger.Printf("Hk=%d Gests=%s", event.HkID, event.Gests)
+// keyRemap holds the actions of a hotkey remapped to another key combo.
+type keyRemap struct {
+	down actions.Action
+	up   actions.Action
+}
+
+func registerRemaps(
+	m *monitor.Monitor,
+	hkIDs hotkeyIDs,
+	mapping config.Mapping,
+) (map[hotkey.ID]keyRemap, error) {
+	hkRemaps := make(map[hotkey.ID]keyRemap)
+	for alias, mk := range mapping {
+		if mk.Remap == nil {
+			continue
 		}
-		if gas, ok := hkGas[event.HkID]; ok {
-			for _, ga := range gas {
-				if ga.G.matches(event.Gests) {
-					if ga.A != nil {
-						go ga.A()
-					}
-					break
-				}
-			}
+		l := len(mk.Remap)
+		if l == 0 {
+			return nil, fmt.Errorf("empty remap of key alias \"%s\"", alias)
+		}
+		key, modifiers := mk.Remap[l-1], mk.Remap[:l-1]
+		hkID, err := hkIDs.add(m, makeKey(alias, mapping))
+		if err != nil {
+			return nil, err
+		}
+		hkRemaps[hkID] = keyRemap{
+			down: actions.NewKeyDown(nil, key, modifiers...),
+			up:   actions.NewKeyUp(nil, key, modifiers...),
 		}
 	}
+	return hkRemaps, nil
 }
 
 func newGesturesConfig(gs config.GestureSettings) gestures.Config {
@@ -124,6 +207,12 @@ func newGesturesConfig(gs config.GestureSettings) gestures.Config {
 	}
 }
 
+func newForegroundProvider(
+	fs config.ForegroundSettings,
+) (actions.ForegroundProvider, error) {
+	return actions.NewForeground(fs.Provider, fs.Cmd...)
+}
+
 func newSwipesConfig(ss config.SwipeSettings) swipes.Config {
 	return swipes.Config{
 		MinDist:  float64(ss.MinDist),
@@ -131,3 +220,15 @@ func newSwipesConfig(ss config.SwipeSettings) swipes.Config {
 		PollRate: ss.PollRate.Duration(),
 	}
 }
+
+// notifyFailure shows a failed action as a desktop notification.
+func notifyFailure(actionName string, err error, logger log.Logger) {
+	n := actions.Notification{
+		Title:   "Mouser: " + actionName + " failed",
+		Body:    err.Error(),
+		Urgency: actions.UrgencyCritical,
+	}
+	if err := actions.DefaultNotifier().Notify(n); err != nil {
+		logger.Printf("Action=ui:notify Err=%s", err)
+	}
+}
@@ -0,0 +1,491 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/echocrow/Mouser/pkg/config"
+	"github.com/echocrow/Mouser/pkg/hotkeys/gestures"
+	"github.com/echocrow/Mouser/pkg/hotkeys/gestures/swipes"
+	"github.com/echocrow/Mouser/pkg/hotkeys/hotkey"
+	"github.com/echocrow/Mouser/pkg/hotkeys/monitor"
+	"github.com/echocrow/Mouser/pkg/log"
+)
+
+// Engine errors raised by package bootstrap.
+var (
+	ErrAlreadyStarted     = errors.New("engine already started")
+	ErrNotYetStarted      = errors.New("engine not yet started")
+	ErrNoHotkeys          = errors.New("no hotkeys specified")
+	ErrOtherEngineRunning = errors.New("another engine is already running")
+)
+
+// EventKind identifies the type of an engine event.
+type EventKind string
+
+// Engine event kinds.
+const (
+	EventStarted  EventKind = "started"
+	EventStopped  EventKind = "stopped"
+	EventReloaded EventKind = "reloaded"
+	EventGesture  EventKind = "gesture"
+	EventAction   EventKind = "action"
+	EventFailure  EventKind = "failure"
+)
+
+// Event holds an engine event.
+type Event struct {
+	Kind EventKind
+	// Key is the hotkey of gesture & action events.
+	Key hotkey.KeyName
+	// Gests are the gestures of gesture & action events.
+	Gests []gestures.Gesture
+	// Action is the action name of action & failure events.
+	Action string
+	// Err is the error of failure events.
+	Err error
+	T   time.Time
+}
+
+// Hooks holds callbacks of actions triggered by an engine.
+type Hooks struct {
+	// BeforeAction is called before a gesture triggers an action. Returning
+	// false skips the action.
+	BeforeAction func(name string, key hotkey.KeyName) bool
+	// AfterAction is called once an action triggered by a gesture returned,
+	// with the error it returned (if any).
+	AfterAction func(name string, key hotkey.KeyName, err error)
+	// OnFailure is called when an action failed. Actions cancelled by stopping
+	// or reloading the engine are not considered failed.
+	OnFailure func(name string, err error)
+}
+
+// EngineOptions holds the options of an engine.
+//
+// Zero values use the respective defaults.
+type EngineOptions struct {
+	// Monitor is the hotkeys monitor engine; defaults to the platform engine.
+	Monitor monitor.Engine
+	// Hotkeys is the hotkey registry engine; defaults to the platform engine.
+	Hotkeys hotkey.Engine
+	// Pointer is the swipes pointer engine; defaults to a robotgo engine.
+	Pointer swipes.PointerEngine
+	// Driver is the system input/output driver of actions (see
+	// actions.SetDriver); defaults to a robotgo driver.
+	Driver actions.Driver
+	// Logger receives all logs of the engine and its actions (see
+	// actions.SetLogger); defaults to separate loggers per topic.
+	Logger log.Logger
+	// Clock is the source of time of timed actions; defaults to the system
+	// clock.
+	Clock actions.Clock
+	// Registry holds the available actions; defaults to the default registry
+	// (see actions.DefaultRegistry).
+	Registry *actions.Registry
+	Hooks    Hooks
+}
+
+// Engine holds an embeddable mouser instance, i.e. a hotkey & gesture monitor
+// triggering configured actions.
+//
+// Some state is shared process-wide, e.g. the keyboard layout, the action
+// driver, the failure handler, the process supervisor & the process index of
+// package actions. Hence only one engine may run at a time; starting another
+// engine fails with ErrOtherEngineRunning.
+type Engine struct {
+	conf config.Config
+	opts EngineOptions
+	m    *monitor.Monitor
+	run  *engineRun
+	mx   sync.Mutex
+
+	subs   map[chan Event]struct{}
+	subsMx sync.RWMutex
+}
+
+var (
+	runningEngine   *Engine
+	runningEngineMx sync.Mutex
+)
+
+// claimRunning marks e as the running engine of the process.
+func (e *Engine) claimRunning() error {
+	runningEngineMx.Lock()
+	defer runningEngineMx.Unlock()
+	if runningEngine != nil && runningEngine != e {
+		return ErrOtherEngineRunning
+	}
+	runningEngine = e
+	return nil
+}
+
+// releaseRunning unmarks e as the running engine of the process.
+func (e *Engine) releaseRunning() {
+	runningEngineMx.Lock()
+	defer runningEngineMx.Unlock()
+	if runningEngine == e {
+		runningEngine = nil
+	}
+}
+
+// engineRun holds the state of a running engine.
+type engineRun struct {
+	conf    config.Config
+	plugins []*actions.Plugin
+	hkIDs   hotkeyIDs
+	fp      actions.ForegroundProvider
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewEngine creates a new engine based on config conf.
+func NewEngine(conf config.Config) *Engine {
+	return NewEngineCustom(conf, EngineOptions{})
+}
+
+// NewEngineCustom creates a new engine based on config conf with custom
+// options.
+func NewEngineCustom(conf config.Config, opts EngineOptions) *Engine {
+	if opts.Clock == nil {
+		opts.Clock = actions.SystemClock{}
+	}
+	if opts.Registry == nil {
+		opts.Registry = actions.DefaultRegistry()
+	}
+	hkReg := hotkey.NewRegistry(opts.Hotkeys, nil)
+	return &Engine{
+		conf: conf,
+		opts: opts,
+		m:    monitor.New(hkReg, opts.Monitor),
+		subs: make(map[chan Event]struct{}),
+	}
+}
+
+// Start starts monitoring hotkeys and triggering actions.
+func (e *Engine) Start() error {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	if e.run != nil {
+		return ErrAlreadyStarted
+	}
+	if err := e.claimRunning(); err != nil {
+		return err
+	}
+	if err := e.start(e.conf); err != nil {
+		e.releaseRunning()
+		return err
+	}
+	e.emit(Event{Kind: EventStarted})
+	return nil
+}
+
+// Stop stops monitoring hotkeys, cancels & awaits running actions, and shuts
+// down plugins & processes started by actions.
+func (e *Engine) Stop() error {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	if e.run == nil {
+		return ErrNotYetStarted
+	}
+	cs := e.run.conf.Settings.Commands
+	err := e.stop()
+	supErr := actions.DefaultSupervisor().Shutdown(cs.OnStop, cs.StopTimeout.Duration())
+	if err == nil {
+		err = supErr
+	}
+	e.releaseRunning()
+	e.emit(Event{Kind: EventStopped})
+	return err
+}
+
+// Reload replaces the config of e.
+//
+// A running engine is restarted with conf; running actions are cancelled,
+// while processes started in the background by actions keep running. When
+// conf fails to start, the engine is restarted with the previous config, and
+// the error is returned.
+func (e *Engine) Reload(conf config.Config) error {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	prevConf := e.conf
+	e.conf = conf
+	if e.run != nil {
+		if err := e.stop(); err != nil {
+			e.releaseRunning()
+			e.emit(Event{Kind: EventStopped})
+			return err
+		}
+		if err := e.start(conf); err != nil {
+			e.conf = prevConf
+			if rErr := e.start(prevConf); rErr != nil {
+				e.releaseRunning()
+				e.emit(Event{Kind: EventStopped})
+			}
+			return err
+		}
+	}
+	e.emit(Event{Kind: EventReloaded})
+	return nil
+}
+
+// Running reports whether e is running.
+func (e *Engine) Running() bool {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	return e.run != nil
+}
+
+// Subscribe subscribes to the events of e.
+//
+// Events are buffered up to size, and dropped while the buffer is full. The
+// returned cancel function ends the subscription and closes the channel.
+func (e *Engine) Subscribe(size int) (events <-chan Event, cancel func()) {
+	ch := make(chan Event, size)
+	e.subsMx.Lock()
+	e.subs[ch] = struct{}{}
+	e.subsMx.Unlock()
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			e.subsMx.Lock()
+			delete(e.subs, ch)
+			e.subsMx.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+func (e *Engine) emit(ev Event) {
+	ev.T = e.opts.Clock.Now()
+	e.subsMx.RLock()
+	defer e.subsMx.RUnlock()
+	for ch := range e.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// logger gets the logger of topic name.
+func (e *Engine) logger(name string) log.Logger {
+	if e.opts.Logger != nil {
+		return e.opts.Logger
+	}
+	return log.New(name)
+}
+
+func (e *Engine) start(conf config.Config) (err error) {
+	var kl *actions.KeyLayout
+	if layout := conf.Settings.Keyboard.Layout; layout != "" {
+		if kl, err = actions.NewKeyLayout(layout); err != nil {
+			return err
+		}
+	}
+	actions.SetKeyLayout(kl)
+	actions.SetLogger(e.opts.Logger)
+	if e.opts.Driver != nil {
+		actions.SetDriver(e.opts.Driver)
+	}
+
+	if file := conf.Settings.Vars.File; file != "" {
+		if err := actions.DefaultVars().Persist(expandPath(file)); err != nil {
+			return err
+		}
+	}
+
+	if err := actions.CheckShutdownMode(conf.Settings.Commands.OnStop); err != nil {
+		return err
+	}
+
+	failLogger := e.logger("Failure")
+	failNotify := conf.Settings.Failures.Notify
+	onFailure := e.opts.Hooks.OnFailure
+	fail := func(actionName string, err error) {
+		failLogger.Printf("Action=%s Err=%s", actionName, err)
+		if failNotify {
+			notifyFailure(actionName, err, failLogger)
+		}
+		if onFailure != nil {
+			onFailure(actionName, err)
+		}
+		e.emit(Event{Kind: EventFailure, Action: actionName, Err: err})
+	}
+	actions.SetFailureHandler(fail)
+
+	plugins, err := startPlugins(e.opts.Registry, conf.Plugins)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			stopPlugins(plugins)
+		}
+	}()
+
+	fp, err := newForegroundProvider(conf.Settings.Foreground)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			actions.CloseForeground(fp)
+		}
+	}()
+
+	hkIDs := make(hotkeyIDs)
+	defer func() {
+		if err != nil {
+			hkIDs.removeAll(e.m)
+		}
+	}()
+	hkGas, err := registerGestures(e.m, hkIDs, conf, fp, e.opts, e.logger)
+	if err != nil {
+		return err
+	}
+	hkRemaps, err := registerRemaps(e.m, hkIDs, conf.Mappings)
+	if err != nil {
+		return err
+	}
+	if len(hkIDs) == 0 {
+		return ErrNoHotkeys
+	}
+
+	var evLogger log.Logger
+	if conf.Settings.Debug {
+		evLogger = e.logger("Gesture")
+		e.m.SetLogCb(e.logger("Monitor").Printf)
+	} else {
+		e.m.SetLogCb(nil)
+	}
+
+	hkEvs, err := e.m.Start()
+	if err != nil {
+		return err
+	}
+	actions.DefaultProcessIndex().Start(conf.Settings.Processes.RefreshRate.Duration())
+	swpConf := newSwipesConfig(conf.Settings.Swipes)
+	gestCh := gestures.FromHotkeysCustom(
+		hkEvs,
+		newGesturesConfig(conf.Settings.Gestures),
+		swipes.NewPointerMonitor(swpConf, e.opts.Pointer),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.watchEvs(ctx, gestCh, hkGas, hkRemaps, hkIDs.keys(), fail, evLogger)
+	}()
+
+	e.run = &engineRun{
+		conf:    conf,
+		plugins: plugins,
+		hkIDs:   hkIDs,
+		fp:      fp,
+		cancel:  cancel,
+		done:    done,
+	}
+	return nil
+}
+
+func (e *Engine) stop() error {
+	r := e.run
+	e.run = nil
+	r.cancel()
+	actions.DefaultProcessIndex().Stop()
+	err := e.m.Stop()
+	// Await running actions before releasing their held keys.
+	<-r.done
+	r.hkIDs.removeAll(e.m)
+	actions.CloseForeground(r.fp)
+	actions.ReleaseHeldKeys()
+	if plErr := stopPlugins(r.plugins); err == nil {
+		err = plErr
+	}
+	return err
+}
+
+// watchEvs triggers the actions & remaps of gesture events, and reports
+// failed actions via fail.
+//
+// Actions are cancelled once ctx is done, and awaited once gestCh is closed.
+func (e *Engine) watchEvs(
+	ctx context.Context,
+	gestCh <-chan gestures.Event,
+	hkGas map[hotkey.ID][]gestureAction,
+	hkRemaps map[hotkey.ID]keyRemap,
+	hkKeys map[hotkey.ID]hotkey.KeyName,
+	fail actions.FailureHandler,
+	logger log.Logger,
+) {
+	hooks := e.opts.Hooks
+	var running sync.WaitGroup
+	defer running.Wait()
+	for event := range gestCh {
+		if logger != nil {
+			logger.Printf("Hk=%d Gests=%s", event.HkID, event.Gests)
+		}
+		key := hkKeys[event.HkID]
+		e.emit(Event{Kind: EventGesture, Key: key, Gests: event.Gests})
+		trig := newTrigger(key, event.Gests, e.opts.Clock.Now())
+		// Remaps are sent synchronously to preserve the key event order.
+		if r, ok := hkRemaps[event.HkID]; ok {
+			var err error
+			if gestures.MatchSingle(event.Gests, gestures.KeyDown) {
+				err = r.down(ctx, trig)
+			} else if gestures.MatchSingle(event.Gests, gestures.KeyUp) {
+				err = r.up(ctx, trig)
+			}
+			if err != nil {
+				fail("remap", err)
+			}
+		}
+		if gas, ok := hkGas[event.HkID]; ok {
+			for _, ga := range gas {
+				if ga.G.matches(event.Gests) {
+					if ga.A != nil {
+						ev := Event{Kind: EventAction, Key: key, Gests: event.Gests, Action: ga.N}
+						running.Add(1)
+						go func(ga gestureAction) {
+							defer running.Done()
+							if hooks.BeforeAction != nil && !hooks.BeforeAction(ga.N, key) {
+								return
+							}
+							e.emit(ev)
+							err := ga.A(ctx, trig)
+							if err != nil && !isCancellation(ctx, err) {
+								fail(ga.N, err)
+							}
+							if hooks.AfterAction != nil {
+								hooks.AfterAction(ga.N, key, err)
+							}
+						}(ga)
+					}
+					break
+				}
+			}
+		}
+	}
+}
+
+// newTrigger creates the trigger of actions of gestures gests of key key.
+func newTrigger(
+	key hotkey.KeyName,
+	gests []gestures.Gesture,
+	t time.Time,
+) actions.Trigger {
+	gs := make([]string, len(gests))
+	for i, g := range gests {
+		gs[i] = string(g)
+	}
+	return actions.Trigger{Key: string(key), Gestures: gs, T: t}
+}
+
+// isCancellation reports whether err stems from ctx being cancelled.
+func isCancellation(ctx context.Context, err error) bool {
+	return ctx.Err() != nil && errors.Is(err, context.Canceled)
+}
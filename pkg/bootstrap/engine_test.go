@@ -0,0 +1,324 @@
+package bootstrap_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/echocrow/Mouser/pkg/actions"
+	"github.com/echocrow/Mouser/pkg/bootstrap"
+	"github.com/echocrow/Mouser/pkg/config"
+	"github.com/echocrow/Mouser/pkg/hotkeys/gestures"
+	"github.com/echocrow/Mouser/pkg/hotkeys/gestures/swipes"
+	"github.com/echocrow/Mouser/pkg/hotkeys/hotkey"
+	hkMocks "github.com/echocrow/Mouser/pkg/hotkeys/hotkey/mocks"
+	"github.com/echocrow/Mouser/pkg/hotkeys/monitor"
+	monMocks "github.com/echocrow/Mouser/pkg/hotkeys/monitor/mocks"
+	"github.com/echocrow/Mouser/pkg/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type testPointerEngine struct{}
+
+func (testPointerEngine) GetPointerPos() vec.Vec2D              { return vec.Vec2D{} }
+func (testPointerEngine) Init(ptEvs chan<- swipes.PointerEvent) {}
+func (testPointerEngine) Resume()                               {}
+func (testPointerEngine) Pause()                                {}
+func (testPointerEngine) Stop()                                 {}
+
+func parseTestConfig(t *testing.T, yml string) config.Config {
+	conf, err := config.ParseYAML([]byte(yml))
+	require.NoError(t, err)
+	return conf
+}
+
+// newTestEngine creates an engine based on mock engines, and gets the
+// monitor once the engine started, and the registered hotkey IDs.
+func newTestEngine(
+	t *testing.T,
+	conf config.Config,
+	opts bootstrap.EngineOptions,
+) (*bootstrap.Engine, <-chan *monitor.Monitor, *sync.Map) {
+	mons := make(chan *monitor.Monitor, 4)
+	me := new(monMocks.Engine)
+	me.On("Init").Return(true)
+	me.On("Start", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		mons <- args.Get(0).(*monitor.Monitor)
+	})
+	me.On("Stop").Return()
+	me.On("Deinit").Return(true)
+	me.On("SetLogCb", mock.Anything).Return()
+	he := new(hkMocks.Engine)
+	hkIDs := new(sync.Map)
+	he.On("Register", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		hkIDs.Store(args.Get(1).(hotkey.KeyName), args.Get(0).(hotkey.ID))
+	})
+	he.On("Unregister", mock.Anything).Return()
+
+	opts.Monitor = me
+	opts.Hotkeys = he
+	opts.Pointer = testPointerEngine{}
+	return bootstrap.NewEngineCustom(conf, opts), mons, hkIDs
+}
+
+func TestEngine(t *testing.T) {
+	reg := actions.NewRegistry()
+	pings := make(chan string, 4)
+	ping := func(msg string) actions.Action {
+		return func(context.Context, actions.Trigger) error {
+			pings <- msg
+			return nil
+		}
+	}
+	require.NoError(t, reg.RegisterBasic("test:ping", ping("ping")))
+	require.NoError(t, reg.RegisterBasic("test:skip", ping("skip")))
+
+	var after []string
+	afterDone := make(chan struct{}, 4)
+	e, mons, hkIDs := newTestEngine(t, parseTestConfig(t, `
+gestures:
+  f1:
+    key_down: test:ping
+  f2:
+    key_down: test:skip
+`), bootstrap.EngineOptions{
+		Registry: reg,
+		Clock:    actions.NewFakeClock(time.Unix(0, 0)),
+		Hooks: bootstrap.Hooks{
+			BeforeAction: func(name string, key hotkey.KeyName) bool {
+				return name != "test:skip"
+			},
+			AfterAction: func(name string, key hotkey.KeyName, err error) {
+				assert.NoError(t, err)
+				after = append(after, name+"@"+string(key))
+				afterDone <- struct{}{}
+			},
+		},
+	})
+	evs, cancel := e.Subscribe(16)
+	defer cancel()
+
+	assert.ErrorIs(t, e.Stop(), bootstrap.ErrNotYetStarted)
+	require.NoError(t, e.Start())
+	assert.ErrorIs(t, e.Start(), bootstrap.ErrAlreadyStarted)
+	assert.True(t, e.Running())
+	m := <-mons
+
+	f1, _ := hkIDs.Load(hotkey.KeyName("f1"))
+	f2, _ := hkIDs.Load(hotkey.KeyName("f2"))
+	require.NoError(t, m.Dispatch(monitor.HotkeyEvent{HkID: f2.(hotkey.ID), IsOn: true}))
+	require.NoError(t, m.Dispatch(monitor.HotkeyEvent{HkID: f1.(hotkey.ID), IsOn: true}))
+	assert.Equal(t, "ping", <-pings)
+	<-afterDone
+	assert.Equal(t, []string{"test:ping@f1"}, after)
+
+	require.NoError(t, e.Stop())
+	assert.False(t, e.Running())
+	assert.Empty(t, pings)
+
+	var kinds []bootstrap.EventKind
+	var actionEv bootstrap.Event
+	for len(evs) > 0 {
+		ev := <-evs
+		kinds = append(kinds, ev.Kind)
+		if ev.Kind == bootstrap.EventAction {
+			actionEv = ev
+		}
+	}
+	assert.Equal(t, []bootstrap.EventKind{
+		bootstrap.EventStarted,
+		bootstrap.EventGesture,
+		bootstrap.EventGesture,
+		bootstrap.EventAction,
+		bootstrap.EventStopped,
+	}, kinds)
+	assert.Equal(t, "test:ping", actionEv.Action)
+	assert.Equal(t, hotkey.KeyName("f1"), actionEv.Key)
+	assert.Equal(t, []gestures.Gesture{gestures.KeyDown}, actionEv.Gests)
+	assert.Equal(t, time.Unix(0, 0), actionEv.T)
+}
+
+func TestEngineClockConditions(t *testing.T) {
+	reg := actions.NewRegistry()
+	pings := make(chan string, 4)
+	ping := func(msg string) actions.Action {
+		return func(context.Context, actions.Trigger) error {
+			pings <- msg
+			return nil
+		}
+	}
+	require.NoError(t, reg.RegisterBasic("test:then", ping("then")))
+	require.NoError(t, reg.RegisterBasic("test:else", ping("else")))
+
+	// Wednesday, 00:30.
+	clk := actions.NewFakeClock(time.Date(2021, 2, 3, 0, 30, 0, 0, time.Local))
+	e, mons, hkIDs := newTestEngine(t, parseTestConfig(t, `
+actions:
+  at-night:
+    type: when
+    if:
+      - time: 00:30-00:31
+        weekday: [wed]
+    then: test:then
+    else: test:else
+gestures:
+  f1:
+    key_down: at-night
+`), bootstrap.EngineOptions{Registry: reg, Clock: clk})
+
+	require.NoError(t, e.Start())
+	defer e.Stop()
+	m := <-mons
+	f1, _ := hkIDs.Load(hotkey.KeyName("f1"))
+
+	require.NoError(t, m.Dispatch(monitor.HotkeyEvent{HkID: f1.(hotkey.ID), IsOn: true}))
+	assert.Equal(t, "then", <-pings)
+
+	clk.Advance(time.Hour * 24)
+	require.NoError(t, m.Dispatch(monitor.HotkeyEvent{HkID: f1.(hotkey.ID), IsOn: true}))
+	assert.Equal(t, "else", <-pings)
+}
+
+func TestEngineReload(t *testing.T) {
+	reg := actions.NewRegistry()
+	require.NoError(t, reg.RegisterBasic("test:ping", func(context.Context, actions.Trigger) error {
+		return nil
+	}))
+
+	e, mons, _ := newTestEngine(t, parseTestConfig(t, `
+gestures:
+  f1:
+    key_down: test:ping
+`), bootstrap.EngineOptions{Registry: reg})
+	evs, cancel := e.Subscribe(16)
+	defer cancel()
+
+	// Reloading a stopped engine only replaces its config.
+	require.NoError(t, e.Reload(parseTestConfig(t, `
+gestures:
+  f2:
+    key_down: test:ping
+`)))
+	assert.False(t, e.Running())
+	assert.Equal(t, bootstrap.EventReloaded, (<-evs).Kind)
+
+	require.NoError(t, e.Start())
+	<-mons
+	assert.Equal(t, bootstrap.EventStarted, (<-evs).Kind)
+
+	require.NoError(t, e.Reload(parseTestConfig(t, `
+gestures:
+  f3:
+    key_down: test:ping
+`)))
+	assert.True(t, e.Running())
+	<-mons
+	assert.Equal(t, bootstrap.EventReloaded, (<-evs).Kind)
+
+	// Failed reloads restore the previous config.
+	err := e.Reload(parseTestConfig(t, `
+gestures:
+  f4:
+    key_down: test:unknown
+`))
+	assert.ErrorIs(t, err, actions.ErrInvalidActionName)
+	assert.True(t, e.Running())
+	<-mons
+	err = e.Reload(config.Config{Settings: config.DefaultSettings})
+	assert.ErrorIs(t, err, bootstrap.ErrNoHotkeys)
+	assert.True(t, e.Running())
+	<-mons
+
+	require.NoError(t, e.Stop())
+	assert.Equal(t, bootstrap.EventStopped, (<-evs).Kind)
+	assert.Empty(t, evs)
+}
+
+func TestEngineSingleRunning(t *testing.T) {
+	reg := actions.NewRegistry()
+	require.NoError(t, reg.RegisterBasic("test:ping", func(context.Context, actions.Trigger) error {
+		return nil
+	}))
+	conf := parseTestConfig(t, `
+gestures:
+  f1:
+    key_down: test:ping
+`)
+	e1, mons1, _ := newTestEngine(t, conf, bootstrap.EngineOptions{Registry: reg})
+	e2, mons2, _ := newTestEngine(t, conf, bootstrap.EngineOptions{Registry: reg})
+
+	require.NoError(t, e1.Start())
+	<-mons1
+	assert.ErrorIs(t, e2.Start(), bootstrap.ErrOtherEngineRunning)
+	assert.False(t, e2.Running())
+
+	require.NoError(t, e1.Stop())
+	require.NoError(t, e2.Start())
+	<-mons2
+	assert.ErrorIs(t, e1.Start(), bootstrap.ErrOtherEngineRunning)
+	require.NoError(t, e2.Stop())
+}
+
+func TestEngineActionErrors(t *testing.T) {
+	errFoo := errors.New("foo")
+	reg := actions.NewRegistry()
+	triggers := make(chan actions.Trigger, 1)
+	require.NoError(t, reg.RegisterBasic("test:fail", func(_ context.Context, ev actions.Trigger) error {
+		triggers <- ev
+		return errFoo
+	}))
+	blocked := make(chan struct{})
+	require.NoError(t, reg.RegisterBasic("test:block", func(ctx context.Context, _ actions.Trigger) error {
+		close(blocked)
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		return ctx.Err()
+	}))
+
+	var failures []string
+	afterErrs := make(chan error, 2)
+	e, mons, hkIDs := newTestEngine(t, parseTestConfig(t, `
+gestures:
+  f1:
+    key_down: test:fail
+  f2:
+    key_down: test:block
+`), bootstrap.EngineOptions{
+		Registry: reg,
+		Hooks: bootstrap.Hooks{
+			AfterAction: func(name string, key hotkey.KeyName, err error) {
+				afterErrs <- err
+			},
+			OnFailure: func(name string, err error) {
+				failures = append(failures, name+": "+err.Error())
+			},
+		},
+	})
+	require.NoError(t, e.Start())
+	m := <-mons
+
+	f1, _ := hkIDs.Load(hotkey.KeyName("f1"))
+	f2, _ := hkIDs.Load(hotkey.KeyName("f2"))
+	require.NoError(t, m.Dispatch(monitor.HotkeyEvent{HkID: f1.(hotkey.ID), IsOn: true}))
+	ev := <-triggers
+	assert.Equal(t, "f1", ev.Key)
+	assert.Equal(t, []string{string(gestures.KeyDown)}, ev.Gestures)
+	assert.ErrorIs(t, <-afterErrs, errFoo)
+	assert.Equal(t, []string{"test:fail: foo"}, failures)
+
+	// Stopping cancels & awaits running actions without reporting failures.
+	require.NoError(t, m.Dispatch(monitor.HotkeyEvent{HkID: f2.(hotkey.ID), IsOn: true}))
+	<-blocked
+	require.NoError(t, e.Stop())
+	select {
+	case err := <-afterErrs:
+		assert.ErrorIs(t, err, context.Canceled)
+	default:
+		assert.Fail(t, "engine stopped before running actions returned")
+	}
+	assert.Equal(t, []string{"test:fail: foo"}, failures)
+}
@@ -26,12 +26,72 @@ var DefaultToggleAction = ToggleAction{
 	RepeatDelay: DefaultSettings.Toggles.RepeatDelay,
 }
 
+// ThrottleAction is an action triggered at most once per cooldown.
+type ThrottleAction struct {
+	Action   ActionRef
+	Cooldown Ms
+	Leading  bool
+	Trailing bool
+}
+
+// DefaultThrottleAction is a ThrottleAction with default settings.
+var DefaultThrottleAction = ThrottleAction{
+	Cooldown: 500,
+	Leading:  true,
+}
+
+// DebounceAction is an action triggered once its triggers paused for a
+// cooldown.
+type DebounceAction struct {
+	Action   ActionRef
+	Cooldown Ms
+	Leading  bool
+	Trailing bool
+}
+
+// DefaultDebounceAction is a DebounceAction with default settings.
+var DefaultDebounceAction = DebounceAction{
+	Cooldown: 500,
+	Trailing: true,
+}
+
+// ConfirmAction is an action that only triggers when confirmed by a second
+// trigger.
+type ConfirmAction struct {
+	Action   ActionRef
+	Window   Ms
+	Feedback string
+	Message  string
+}
+
+// DefaultConfirmAction is a ConfirmAction with default settings.
+var DefaultConfirmAction = ConfirmAction{
+	Window: 2000,
+}
+
+// CycleAction is an action stepping through a list of actions on each
+// trigger.
+type CycleAction struct {
+	Actions    []ActionRef
+	Mode       string
+	ResetAfter Ms `yaml:"reset-after"`
+}
+
 // AppBranchAction is a foreground-app-specific action.
 type AppBranchAction struct {
+	Windows  []WindowBranch
 	Branches map[string]ActionRef
 	Fallback ActionRef
 }
 
+// WindowBranch is a foreground-window-specific action branch.
+type WindowBranch struct {
+	App     string
+	Title   string
+	TitleRe string `yaml:"title-re"`
+	Do      ActionRef
+}
+
 // RequireAppAction is a running-app-dependent action.
 type RequireAppAction struct {
 	App      string
@@ -39,6 +99,59 @@ type RequireAppAction struct {
 	Fallback ActionRef
 }
 
+// VarBranchAction is a state-variable-dependent action.
+type VarBranchAction struct {
+	Var      string
+	Branches map[string]ActionRef
+	Fallback ActionRef
+}
+
+// WhenAction is a condition-dependent action.
+type WhenAction struct {
+	If   []WhenCondition
+	Then ActionRef
+	Else ActionRef
+}
+
+// WhenCondition describes a set of predicates of a conditional action.
+//
+// All non-empty predicates of a condition must be met for the condition to
+// be met.
+type WhenCondition struct {
+	Time    string
+	Weekday StringList
+	Host    string
+	Env     string
+	File    string
+	Cmd     StringList
+}
+
+// StringList holds a list of strings.
+//
+// A StringList may be decoded from a single string or from a list of strings.
+type StringList []string
+
+// UnmarshalYAML decodes a StringList YAML node.
+func (sl *StringList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var str string
+		if err := node.Decode(&str); err != nil {
+			return err
+		}
+		*sl = StringList{str}
+	case yaml.SequenceNode:
+		var strs []string
+		if err := node.Decode(&strs); err != nil {
+			return err
+		}
+		*sl = strs
+	default:
+		return newYAMLConfigError(node, "value must be a string or list")
+	}
+	return nil
+}
+
 func getActionNodeType(node *yaml.Node) (string, error) {
 	if typeNode, err := findChildNode(node, "type"); err != nil {
 		return "", err
@@ -80,6 +193,22 @@ func (ref *ActionRef) UnmarshalYAML(node *yaml.Node) error {
 			a := DefaultToggleAction
 			err = node.Decode(&a)
 			ref.A = a
+		case "throttle":
+			a := DefaultThrottleAction
+			err = node.Decode(&a)
+			ref.A = a
+		case "debounce":
+			a := DefaultDebounceAction
+			err = node.Decode(&a)
+			ref.A = a
+		case "confirm":
+			a := DefaultConfirmAction
+			err = node.Decode(&a)
+			ref.A = a
+		case "cycle":
+			a := CycleAction{}
+			err = node.Decode(&a)
+			ref.A = a
 		case "app-branch":
 			a := AppBranchAction{}
 			err = node.Decode(&a)
@@ -88,6 +217,14 @@ func (ref *ActionRef) UnmarshalYAML(node *yaml.Node) error {
 			a := RequireAppAction{}
 			err = node.Decode(&a)
 			ref.A = a
+		case "when":
+			a := WhenAction{}
+			err = node.Decode(&a)
+			ref.A = a
+		case "var-branch":
+			a := VarBranchAction{}
+			err = node.Decode(&a)
+			ref.A = a
 		default:
 			err = newYAMLConfigError(node, "unknown action type \"%s\"", actionType)
 		}
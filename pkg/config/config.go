@@ -33,7 +33,8 @@ func findChildNode(node *yaml.Node, key string) (*yaml.Node, error) {
 type KeyAlias string
 
 type mappingKey struct {
-	Key string
+	Key   string
+	Remap StringList
 }
 
 // MappingKey describes a key mapping to a key alias.
@@ -66,9 +67,17 @@ type Config struct {
 	Mappings Mapping
 	Gestures map[KeyAlias]GestureActions
 	Actions  map[string]ActionRef
+	Plugins  []PluginConfig
 	Settings Settings
 }
 
+// PluginConfig describes an external executable providing actions.
+type PluginConfig struct {
+	Cmd           StringList
+	Timeout       Ms
+	InvokeTimeout Ms `yaml:"invoke-timeout"`
+}
+
 // ParseYAML decodes a YAML document into a mouser config.
 func ParseYAML(contents []byte) (Config, error) {
 	config := Config{
@@ -14,8 +14,16 @@ type Conf = config.Config
 type ARef = config.ActionRef
 type BasicA = config.BasicAction
 type ToggleA = config.ToggleAction
+type CycleA = config.CycleAction
+type ConfirmA = config.ConfirmAction
+type ThrottleA = config.ThrottleAction
+type DebounceA = config.DebounceAction
 type AppBrA = config.AppBranchAction
+type WinBr = config.WindowBranch
 type ReqAppA = config.RequireAppAction
+type WhenA = config.WhenAction
+type WhenC = config.WhenCondition
+type VarBrA = config.VarBranchAction
 
 type Gests = config.GestureSeries
 
@@ -124,6 +132,124 @@ func TestParseYAML(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"throttle & debounce",
+			`
+      actions:
+        foo:throttled:
+          type: throttle
+          action: foo
+        bar:throttled:
+          type: throttle
+          action: {action: bar, args: [1]}
+          cooldown: 1000
+          leading: false
+          trailing: true
+        foo:debounced:
+          type: debounce
+          action: foo
+        bar:debounced:
+          type: debounce
+          action: bar
+          cooldown: 200
+          leading: true
+          trailing: false
+      `,
+			Conf{
+				Actions: map[string]ARef{
+					"foo:throttled": {ThrottleA{
+						Action:   ARef{BasicA{Name: "foo"}},
+						Cooldown: 500,
+						Leading:  true,
+					}},
+					"bar:throttled": {ThrottleA{
+						Action:   ARef{BasicA{Name: "bar", Args: []interface{}{1}}},
+						Cooldown: 1000,
+						Trailing: true,
+					}},
+					"foo:debounced": {DebounceA{
+						Action:   ARef{BasicA{Name: "foo"}},
+						Cooldown: 500,
+						Trailing: true,
+					}},
+					"bar:debounced": {DebounceA{
+						Action:   ARef{BasicA{Name: "bar"}},
+						Cooldown: 200,
+						Leading:  true,
+					}},
+				},
+				Settings: ds,
+			},
+			true,
+		},
+		{
+			"confirm",
+			`
+      actions:
+        foo:confirmed:
+          type: confirm
+          action: foo
+        bar:confirmed:
+          type: confirm
+          action: {action: bar, args: [1]}
+          window: 3000
+          feedback: log
+          message: Really?
+      `,
+			Conf{
+				Actions: map[string]ARef{
+					"foo:confirmed": {ConfirmA{
+						Action: ARef{BasicA{Name: "foo"}},
+						Window: 2000,
+					}},
+					"bar:confirmed": {ConfirmA{
+						Action:   ARef{BasicA{Name: "bar", Args: []interface{}{1}}},
+						Window:   3000,
+						Feedback: "log",
+						Message:  "Really?",
+					}},
+				},
+				Settings: ds,
+			},
+			true,
+		},
+		{
+			"cycle",
+			`
+      actions:
+        foo:cycle:
+          type: cycle
+          actions:
+            - foo:one
+            - action: foo:two
+              args: [2]
+        bar:cycle:
+          type: cycle
+          mode: ping-pong
+          reset-after: 1500
+          actions: [bar:one, bar:two]
+      `,
+			Conf{
+				Actions: map[string]ARef{
+					"foo:cycle": {CycleA{
+						Actions: []ARef{
+							{BasicA{Name: "foo:one"}},
+							{BasicA{Name: "foo:two", Args: []interface{}{2}}},
+						},
+					}},
+					"bar:cycle": {CycleA{
+						Actions: []ARef{
+							{BasicA{Name: "bar:one"}},
+							{BasicA{Name: "bar:two"}},
+						},
+						Mode:       "ping-pong",
+						ResetAfter: 1500,
+					}},
+				},
+				Settings: ds,
+			},
+			true,
+		},
 		{
 			"app branch",
 			`
@@ -153,6 +279,57 @@ func TestParseYAML(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"app branch with windows",
+			`
+      actions:
+        foo:special:
+          type: app-branch
+          windows:
+            - app: /App/Foo
+              title: Bar
+              do: foo:bar:action
+            - title-re: "^Baz #\\d+"
+              do:
+                action: baz:action
+                args: [1]
+            - title: Skip
+              do: null
+          branches:
+            /App/Foo: foo:action
+          fallback: fall:back:action
+      `,
+			Conf{
+				Actions: map[string]ARef{
+					"foo:special": {AppBrA{
+						Windows: []WinBr{
+							{
+								App:   "/App/Foo",
+								Title: "Bar",
+								Do:    ARef{BasicA{Name: "foo:bar:action"}},
+							},
+							{
+								TitleRe: `^Baz #\d+`,
+								Do: ARef{BasicA{
+									Name: "baz:action",
+									Args: []interface{}{1},
+								}},
+							},
+							{
+								Title: "Skip",
+								Do:    ARef{},
+							},
+						},
+						Branches: map[string]ARef{
+							"/App/Foo": {BasicA{Name: "foo:action"}},
+						},
+						Fallback: ARef{BasicA{Name: "fall:back:action"}},
+					}},
+				},
+				Settings: ds,
+			},
+			true,
+		},
 		{
 			"require app",
 			`
@@ -175,6 +352,93 @@ func TestParseYAML(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"when",
+			`
+      actions:
+        foo:when:
+          type: when
+          if:
+            - time: 09:00-17:00
+              weekday: mon-fri
+            - host: foo-host
+            - env: FOO=bar
+            - file: ~/foo
+            - cmd: [foo, --bar]
+            - weekday: [sat, sun]
+              cmd: foo
+          then: foo:action
+          else: fall:back:action
+      `,
+			Conf{
+				Actions: map[string]ARef{
+					"foo:when": {WhenA{
+						If: []WhenC{
+							{Time: "09:00-17:00", Weekday: config.StringList{"mon-fri"}},
+							{Host: "foo-host"},
+							{Env: "FOO=bar"},
+							{File: "~/foo"},
+							{Cmd: config.StringList{"foo", "--bar"}},
+							{
+								Weekday: config.StringList{"sat", "sun"},
+								Cmd:     config.StringList{"foo"},
+							},
+						},
+						Then: ARef{BasicA{Name: "foo:action"}},
+						Else: ARef{BasicA{Name: "fall:back:action"}},
+					}},
+				},
+				Settings: ds,
+			},
+			true,
+		},
+		{
+			"var branch",
+			`
+      actions:
+        foo:mode:
+          type: var-branch
+          var: mode
+          branches:
+            edit: foo:edit
+            true: foo:on
+            1: foo:one
+          fallback: foo:default
+      `,
+			Conf{
+				Actions: map[string]ARef{
+					"foo:mode": {VarBrA{
+						Var: "mode",
+						Branches: map[string]ARef{
+							"edit": {BasicA{Name: "foo:edit"}},
+							"true": {BasicA{Name: "foo:on"}},
+							"1":    {BasicA{Name: "foo:one"}},
+						},
+						Fallback: ARef{BasicA{Name: "foo:default"}},
+					}},
+				},
+				Settings: ds,
+			},
+			true,
+		},
+		{
+			"plugins",
+			`
+      plugins:
+        - cmd: ~/bin/foo-actions
+        - cmd: [bar-actions, --verbose]
+          timeout: 1000
+          invoke-timeout: 60000
+      `,
+			Conf{
+				Plugins: []config.PluginConfig{
+					{Cmd: config.StringList{"~/bin/foo-actions"}},
+					{Cmd: config.StringList{"bar-actions", "--verbose"}, Timeout: 1000, InvokeTimeout: 60000},
+				},
+				Settings: ds,
+			},
+			true,
+		},
 		{
 			"simple settings",
 			`
@@ -198,7 +462,9 @@ func TestParseYAML(t *testing.T) {
 						Throttle: ds.Swipes.Throttle,
 						PollRate: ds.Swipes.PollRate,
 					},
-					Toggles: ds.Toggles,
+					Toggles:   ds.Toggles,
+					Processes: ds.Processes,
+					Commands:  ds.Commands,
 				},
 			},
 			true,
@@ -210,6 +476,7 @@ func TestParseYAML(t *testing.T) {
         K1: fookey
         K2: barkey
         K3: {key: bazkey}
+        K4: {key: quxkey, remap: [cmd, "["]}
 
       gestures:
         K1:
@@ -303,12 +570,27 @@ func TestParseYAML(t *testing.T) {
         toggles:
           init-delay: 111
           repeat-delay: 222
+        foreground:
+          provider: cmd
+          cmd: [foo, --bar]
+        processes:
+          refresh-rate: 333
+        keyboard:
+          layout: azerty
+        commands:
+          on-stop: kill
+          stop-timeout: 444
+        failures:
+          notify: true
+        vars:
+          file: ~/.mouser-vars.json
       `,
 			Conf{
 				Mappings: map[config.KeyAlias]config.MappingKey{
 					"K1": {Key: "fookey"},
 					"K2": {Key: "barkey"},
 					"K3": {Key: "bazkey"},
+					"K4": {Key: "quxkey", Remap: config.StringList{"cmd", "["}},
 				},
 				Gestures: map[config.KeyAlias]config.GestureActions{
 					"K1": {
@@ -431,6 +713,26 @@ func TestParseYAML(t *testing.T) {
 						InitDelay:   111,
 						RepeatDelay: 222,
 					},
+					Foreground: config.ForegroundSettings{
+						Provider: "cmd",
+						Cmd:      config.StringList{"foo", "--bar"},
+					},
+					Processes: config.ProcessSettings{
+						RefreshRate: 333,
+					},
+					Keyboard: config.KeyboardSettings{
+						Layout: "azerty",
+					},
+					Commands: config.CommandSettings{
+						OnStop:      "kill",
+						StopTimeout: 444,
+					},
+					Failures: config.FailureSettings{
+						Notify: true,
+					},
+					Vars: config.VarSettings{
+						File: "~/.mouser-vars.json",
+					},
 				},
 			},
 			true,
@@ -482,6 +784,19 @@ func TestParseYAML(t *testing.T) {
         foo:action:
           type: invalid_type
           action: bar:action
+      `,
+			Conf{},
+			false,
+		},
+		{
+			"invalid when condition",
+			`
+      actions:
+        foo:action:
+          type: when
+          if:
+            - weekday: {mon: true}
+          then: bar:action
       `,
 			Conf{},
 			false,
@@ -6,10 +6,16 @@ import (
 
 // Settings contains custom config settings.
 type Settings struct {
-	Debug    bool
-	Gestures GestureSettings
-	Swipes   SwipeSettings
-	Toggles  ToggleSettings
+	Debug      bool
+	Gestures   GestureSettings
+	Swipes     SwipeSettings
+	Toggles    ToggleSettings
+	Foreground ForegroundSettings
+	Processes  ProcessSettings
+	Keyboard   KeyboardSettings
+	Commands   CommandSettings
+	Failures   FailureSettings
+	Vars       VarSettings
 }
 
 // GestureSettings contains custom gesture settings.
@@ -32,6 +38,40 @@ type ToggleSettings struct {
 	RepeatDelay Ms `yaml:"repeat-delay"`
 }
 
+// ForegroundSettings contains custom foreground app detection settings.
+type ForegroundSettings struct {
+	Provider string
+	Cmd      StringList
+}
+
+// ProcessSettings contains custom running process detection settings.
+type ProcessSettings struct {
+	RefreshRate Ms `yaml:"refresh-rate"`
+}
+
+// KeyboardSettings contains custom keyboard settings.
+type KeyboardSettings struct {
+	Layout string
+}
+
+// CommandSettings contains custom settings of commands started by actions.
+type CommandSettings struct {
+	OnStop      string `yaml:"on-stop"`
+	StopTimeout Ms     `yaml:"stop-timeout"`
+}
+
+// FailureSettings contains custom settings of failed action reports.
+type FailureSettings struct {
+	Notify bool
+}
+
+// VarSettings contains custom settings of state variables.
+type VarSettings struct {
+	// File is the path of the JSON file persisting variables; empty keeps
+	// variables in memory only.
+	File string
+}
+
 // Ms represents a time duration in miliseconds.
 type Ms uint
 
@@ -57,4 +97,11 @@ var DefaultSettings = Settings{
 		InitDelay:   200,
 		RepeatDelay: 100,
 	},
+	Processes: ProcessSettings{
+		RefreshRate: 2000,
+	},
+	Commands: CommandSettings{
+		OnStop:      "detach",
+		StopTimeout: 3000,
+	},
 }